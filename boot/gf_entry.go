@@ -5,11 +5,12 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/database/gredis"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/glog"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
@@ -26,24 +27,37 @@ import (
 	"github.com/rookie-ninja/rk-entry/v2/middleware/tracing"
 	"github.com/rookie-ninja/rk-gf/middleware"
 	"github.com/rookie-ninja/rk-gf/middleware/auth"
+	"github.com/rookie-ninja/rk-gf/middleware/client"
 	"github.com/rookie-ninja/rk-gf/middleware/cors"
 	"github.com/rookie-ninja/rk-gf/middleware/csrf"
+	"github.com/rookie-ninja/rk-gf/middleware/gcode"
+	"github.com/rookie-ninja/rk-gf/middleware/gdb"
+	"github.com/rookie-ninja/rk-gf/middleware/gredis"
 	"github.com/rookie-ninja/rk-gf/middleware/jwt"
 	"github.com/rookie-ninja/rk-gf/middleware/log"
 	"github.com/rookie-ninja/rk-gf/middleware/meta"
+	"github.com/rookie-ninja/rk-gf/middleware/otelmetrics"
 	"github.com/rookie-ninja/rk-gf/middleware/panic"
 	"github.com/rookie-ninja/rk-gf/middleware/prom"
 	"github.com/rookie-ninja/rk-gf/middleware/ratelimit"
+	"github.com/rookie-ninja/rk-gf/middleware/response"
 	"github.com/rookie-ninja/rk-gf/middleware/secure"
 	"github.com/rookie-ninja/rk-gf/middleware/tracing"
+	"github.com/rookie-ninja/rk-gf/middleware/validation"
 	"github.com/rookie-ninja/rk-query"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.uber.org/zap"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"os"
 	"path"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -51,6 +65,9 @@ const (
 	GfEntryType = "GoFrameEntry"
 )
 
+// anonymousEntrySeq is used to generate unique name of entries with random port and without name
+var anonymousEntrySeq uint64
+
 // This must be declared in order to register registration function into rk context
 // otherwise, rk-boot won't able to bootstrap GoFrame entry automatically from boot config file
 func init() {
@@ -65,27 +82,41 @@ type BootGf struct {
 		Port          uint64                        `yaml:"port" json:"port"`
 		Description   string                        `yaml:"description" json:"description"`
 		CertEntry     string                        `yaml:"certEntry" json:"certEntry"`
+		TLS           BootTLS                       `yaml:"tls" json:"tls"`
+		Plaintext     BootPlaintext                 `yaml:"plaintext" json:"plaintext"`
+		Listener      BootListener                  `yaml:"listener" json:"listener"`
+		Server        BootServer                    `yaml:"server" json:"server"`
+		Management    BootManagement                `yaml:"management" json:"management"`
 		LoggerEntry   string                        `yaml:"loggerEntry" json:"loggerEntry"`
 		EventEntry    string                        `yaml:"eventEntry" json:"eventEntry"`
 		SW            rkentry.BootSW                `yaml:"sw" json:"sw"`
 		Docs          rkentry.BootDocs              `yaml:"docs" json:"docs"`
 		CommonService rkentry.BootCommonService     `yaml:"commonService" json:"commonService"`
-		Prom          rkentry.BootProm              `yaml:"prom" json:"prom"`
+		Prom          BootProm                      `yaml:"prom" json:"prom"`
 		Static        rkentry.BootStaticFileHandler `yaml:"static" json:"static"`
 		PProf         rkentry.BootPProf             `yaml:"pprof" json:"pprof"`
-		Middleware    struct {
-			Ignore     []string              `yaml:"ignore" json:"ignore"`
-			ErrorModel string                `yaml:"errorModel" json:"errorModel"`
-			Logging    rkmidlog.BootConfig   `yaml:"logging" json:"logging"`
-			Prom       rkmidprom.BootConfig  `yaml:"prom" json:"prom"`
-			Auth       rkmidauth.BootConfig  `yaml:"auth" json:"auth"`
-			Cors       rkmidcors.BootConfig  `yaml:"cors" json:"cors"`
-			Meta       rkmidmeta.BootConfig  `yaml:"meta" json:"meta"`
-			Jwt        rkmidjwt.BootConfig   `yaml:"jwt" json:"jwt"`
-			Secure     rkmidsec.BootConfig   `yaml:"secure" json:"secure"`
-			RateLimit  rkmidlimit.BootConfig `yaml:"rateLimit" json:"rateLimit"`
-			Csrf       rkmidcsrf.BootConfig  `yaml:"csrf" yaml:"csrf"`
-			Trace      rkmidtrace.BootConfig `yaml:"trace" json:"trace"`
+		Clients       []rkgfclient.BootConfig       `yaml:"clients" json:"clients"`
+		Shutdown      struct {
+			DrainTimeoutMs int `yaml:"drainTimeoutMs" json:"drainTimeoutMs"`
+			PreStopDelayMs int `yaml:"preStopDelayMs" json:"preStopDelayMs"`
+		} `yaml:"shutdown" json:"shutdown"`
+		Middleware struct {
+			Ignore      []string                   `yaml:"ignore" json:"ignore"`
+			ErrorModel  string                     `yaml:"errorModel" json:"errorModel"`
+			Logging     rkmidlog.BootConfig        `yaml:"logging" json:"logging"`
+			Prom        rkgfprom.BootConfig        `yaml:"prom" json:"prom"`
+			OtelMetrics rkgfotelmetrics.BootConfig `yaml:"otelMetrics" json:"otelMetrics"`
+			Auth        rkmidauth.BootConfig       `yaml:"auth" json:"auth"`
+			Cors        rkmidcors.BootConfig       `yaml:"cors" json:"cors"`
+			Meta        rkmidmeta.BootConfig       `yaml:"meta" json:"meta"`
+			Jwt         rkmidjwt.BootConfig        `yaml:"jwt" json:"jwt"`
+			Secure      rkmidsec.BootConfig        `yaml:"secure" json:"secure"`
+			RateLimit   rkmidlimit.BootConfig      `yaml:"rateLimit" json:"rateLimit"`
+			Csrf        rkmidcsrf.BootConfig       `yaml:"csrf" yaml:"csrf"`
+			Trace       rkmidtrace.BootConfig      `yaml:"trace" json:"trace"`
+			Gcode       rkgfgcode.BootConfig       `yaml:"gcode" json:"gcode"`
+			Response    rkgfresp.BootConfig        `yaml:"response" json:"response"`
+			Validation  rkgfvalid.BootConfig       `yaml:"validation" json:"validation"`
 		} `yaml:"middleware" json:"middleware"`
 	} `yaml:"gf" json:"gf"`
 }
@@ -108,6 +139,34 @@ type GfEntry struct {
 	PProfEntry         *rkentry.PProfEntry             `json:"-" yaml:"-"`
 	Middlewares        []ghttp.HandlerFunc             `json:"-" yaml:"-"`
 	bootstrapLogOnce   sync.Once                       `json:"-" yaml:"-"`
+	drainTimeout       time.Duration                   `json:"-" yaml:"-"`
+	preStopDelay       time.Duration                   `json:"-" yaml:"-"`
+	tlsClientAuth      tls.ClientAuthType              `json:"-" yaml:"-"`
+	tlsCaEntry         *rkentry.CertEntry              `json:"-" yaml:"-"`
+	tlsMinVersion      uint16                          `json:"-" yaml:"-"`
+	tlsCipherSuites    []uint16                        `json:"-" yaml:"-"`
+	tlsCertPemPath     string                          `json:"-" yaml:"-"`
+	tlsKeyPemPath      string                          `json:"-" yaml:"-"`
+	certReloader       *certReloader                   `json:"-" yaml:"-"`
+	plaintextPort      uint64                          `json:"-" yaml:"-"`
+	plaintextMode      string                          `json:"-" yaml:"-"`
+	plaintextServer    *http.Server                    `json:"-" yaml:"-"`
+	preListener        net.Listener                    `json:"-" yaml:"-"`
+	unixSocketPath     string                          `json:"-" yaml:"-"`
+	unixSocketMode     os.FileMode                     `json:"-" yaml:"-"`
+	systemdSocket      bool                            `json:"-" yaml:"-"`
+	listener           *drainListener                  `json:"-" yaml:"-"`
+	inFlight           int64                           `json:"-" yaml:"-"`
+	draining           int32                           `json:"-" yaml:"-"`
+	reportErrors       bool                            `json:"-" yaml:"-"`
+	fatalErrors        chan error                      `json:"-" yaml:"-"`
+	serverConfig       serverConfig                    `json:"-" yaml:"-"`
+	managementPort     uint64                          `json:"-" yaml:"-"`
+	managementServer   *ghttp.Server                   `json:"-" yaml:"-"`
+	middlewareInfos    []middlewareInfo                `json:"-" yaml:"-"`
+	errorBuilder       rkerror.ErrorBuilder            `json:"-" yaml:"-"`
+	clients            map[string]*rkgfclient.Client   `json:"-" yaml:"-"`
+	meterProvider      *sdkmetric.MeterProvider        `json:"-" yaml:"-"`
 }
 
 // RegisterGfEntryYAML register GoFrame entries with provided config file (Must YAML file).
@@ -159,6 +218,43 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 		// cert entry
 		certEntry := rkentry.GlobalAppCtx.GetCertEntry(element.CertEntry)
 
+		// tls options
+		clientAuth, err := parseClientAuth(element.TLS.ClientAuth)
+		if err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		minVersion, err := parseTlsVersion(element.TLS.MinVersion)
+		if err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		cipherSuites, err := parseCipherSuites(element.TLS.CipherSuites...)
+		if err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		if err := element.TLS.validatePemPath(); err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		switch strings.ToLower(element.Plaintext.Mode) {
+		case "", PlaintextModeRedirect, PlaintextModeInternal:
+		default:
+			rkentry.ShutdownWithError(fmt.Errorf("invalid plaintext.mode:%s, [redirect, internal] are supported", element.Plaintext.Mode))
+		}
+		var unixSocketMode uint64
+		if len(element.Listener.Unix.FileMode) > 0 {
+			if unixSocketMode, err = strconv.ParseUint(element.Listener.Unix.FileMode, 8, 32); err != nil {
+				rkentry.ShutdownWithError(fmt.Errorf("invalid listener.unix.fileMode:%s, octal like 0660 is expected", element.Listener.Unix.FileMode))
+			}
+		}
+		if err := element.Server.validate(); err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		var caEntry *rkentry.CertEntry
+		if len(element.TLS.CaEntry) > 0 {
+			if caEntry = rkentry.GlobalAppCtx.GetCertEntry(element.TLS.CaEntry); caEntry == nil {
+				rkentry.ShutdownWithError(fmt.Errorf("cert entry of tls.caEntry:%s not found", element.TLS.CaEntry))
+			}
+		}
+
 		// Register swagger entry
 		swEntry := rkentry.RegisterSWEntry(&element.SW, rkentry.WithNameSWEntry(element.Name))
 
@@ -167,7 +263,10 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 
 		// Register prometheus entry
 		promRegistry := prometheus.NewRegistry()
-		promEntry := rkentry.RegisterPromEntry(&element.Prom, rkentry.WithRegistryPromEntry(promRegistry))
+		promEntry := rkentry.RegisterPromEntry(&element.Prom.BootProm, rkentry.WithRegistryPromEntry(promRegistry))
+		if promEntry != nil {
+			registerPromCollectors(promRegistry, &element.Prom.Collectors)
+		}
 
 		// Register common service entry
 		commonServiceEntry := rkentry.RegisterCommonServiceEntry(&element.CommonService)
@@ -178,17 +277,61 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 		// Register pprof entry
 		pprofEntry := rkentry.RegisterPProfEntry(&element.PProf, rkentry.WithNamePProfEntry(element.Name))
 
+		// Register outbound clients which share logger and prometheus registry of entry
+		if err := validateClients(element.Clients); err != nil {
+			rkentry.ShutdownWithError(err)
+		}
+		clients := make([]*rkgfclient.Client, 0)
+		for i := range element.Clients {
+			clients = append(clients, rkgfclient.New(
+				rkgfclient.ToOptions(&element.Clients[i], element.Name, GfEntryType,
+					loggerEntry.Logger, promRegistry)...))
+		}
+
+		// Build MeterProvider exporting metrics of OpenTelemetry metrics middleware, global MeterProvider
+		// would be used if no exporter is enabled
+		var meterProvider *sdkmetric.MeterProvider
+		if element.Middleware.OtelMetrics.Enabled {
+			provider, err := rkgfotelmetrics.NewMeterProvider(&element.Middleware.OtelMetrics)
+			if err != nil {
+				rkentry.ShutdownWithError(err)
+			}
+			meterProvider = provider
+		}
+
 		inters := make([]ghttp.HandlerFunc, 0)
 
-		// add global path ignorance
-		rkmid.AddPathToIgnoreGlobal(element.Middleware.Ignore...)
+		// add path ignorance of entry into every middleware, instead of global path ignorance shared by entries
+		for _, ignore := range []*[]string{
+			&element.Middleware.Logging.Ignore,
+			&element.Middleware.Prom.Ignore,
+			&element.Middleware.OtelMetrics.Ignore,
+			&element.Middleware.Trace.Ignore,
+			&element.Middleware.Cors.Ignore,
+			&element.Middleware.Jwt.Ignore,
+			&element.Middleware.Secure.Ignore,
+			&element.Middleware.Csrf.Ignore,
+			&element.Middleware.Meta.Ignore,
+			&element.Middleware.Auth.Ignore,
+			&element.Middleware.RateLimit.Ignore,
+			&element.Middleware.Gcode.Ignore,
+			&element.Middleware.Response.Ignore,
+			&element.Middleware.Validation.Ignore,
+		} {
+			*ignore = append(*ignore, element.Middleware.Ignore...)
+		}
 
-		// set error builder based on error builder
+		// set error builder of entry based on error model
+		var errBuilder rkerror.ErrorBuilder
 		switch strings.ToLower(element.Middleware.ErrorModel) {
 		case "", "google":
-			rkmid.SetErrorBuilder(rkerror.NewErrorBuilderGoogle())
+			errBuilder = rkerror.NewErrorBuilderGoogle()
 		case "amazon":
-			rkmid.SetErrorBuilder(rkerror.NewErrorBuilderAMZN())
+			errBuilder = rkerror.NewErrorBuilderAMZN()
+		case "problem":
+			errBuilder = rkgfinter.NewErrorBuilderProblem()
+		default:
+			rkentry.ShutdownWithError(fmt.Errorf("invalid middleware.errorModel:%s, [google, amazon, problem] are supported", element.Middleware.ErrorModel))
 		}
 
 		// logging middlewares
@@ -204,17 +347,34 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 
 		// metrics middleware
 		if element.Middleware.Prom.Enabled {
-			inters = append(inters, rkgfprom.Middleware(
-				rkmidprom.ToOptions(&element.Middleware.Prom, element.Name, GfEntryType,
+			inters = append(inters, rkgfprom.MiddlewareWithOptions(
+				rkgfprom.ToOptions(&element.Middleware.Prom, element.Name, GfEntryType,
 					promRegistry, rkmidprom.LabelerTypeHttp)...))
 		}
 
+		// OpenTelemetry metrics middleware
+		if element.Middleware.OtelMetrics.Enabled {
+			var provider metric.MeterProvider
+			if meterProvider != nil {
+				provider = meterProvider
+			}
+			inters = append(inters, rkgfotelmetrics.Middleware(
+				rkgfotelmetrics.ToOptions(&element.Middleware.OtelMetrics, element.Name, GfEntryType, provider)...))
+		}
+
 		// tracing middleware
 		if element.Middleware.Trace.Enabled {
 			inters = append(inters, rkgftrace.Middleware(
 				rkmidtrace.ToOptions(&element.Middleware.Trace, element.Name, GfEntryType)...))
 		}
 
+		// response middleware is placed inside metrics and tracing middlewares,
+		// so that they would record final status written by envelope
+		if element.Middleware.Response.Enabled {
+			inters = append(inters, rkgfresp.Middleware(
+				rkgfresp.ToOptions(&element.Middleware.Response, element.Name, GfEntryType)...))
+		}
+
 		// cors middleware
 		if element.Middleware.Cors.Enabled {
 			inters = append(inters, rkgfcors.Middleware(
@@ -257,6 +417,37 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 				rkmidlimit.ToOptions(&element.Middleware.RateLimit, element.Name, GfEntryType)...))
 		}
 
+		// validation middleware
+		if element.Middleware.Validation.Enabled {
+			inters = append(inters, rkgfvalid.Middleware(
+				rkgfvalid.ToOptions(&element.Middleware.Validation, element.Name, GfEntryType)...))
+		}
+
+		// gcode middleware
+		if element.Middleware.Gcode.Enabled {
+			inters = append(inters, rkgfgcode.Middleware(
+				rkgfgcode.ToOptions(&element.Middleware.Gcode, element.Name, GfEntryType)...))
+		}
+
+		// middleware configs in order of chain, exposed by routes endpoint
+		mids := []middlewareInfo{
+			newMiddlewareInfo("logging", element.Middleware.Logging.Enabled, element.Middleware.Logging.Ignore, element.Middleware.Logging),
+			{Name: "panic", Enabled: true, Ignore: []string{}, Config: struct{}{}},
+			newMiddlewareInfo("prom", element.Middleware.Prom.Enabled, element.Middleware.Prom.Ignore, element.Middleware.Prom),
+			newMiddlewareInfo("otelMetrics", element.Middleware.OtelMetrics.Enabled, element.Middleware.OtelMetrics.Ignore, element.Middleware.OtelMetrics),
+			newMiddlewareInfo("trace", element.Middleware.Trace.Enabled, element.Middleware.Trace.Ignore, element.Middleware.Trace),
+			newMiddlewareInfo("response", element.Middleware.Response.Enabled, element.Middleware.Response.Ignore, element.Middleware.Response),
+			newMiddlewareInfo("cors", element.Middleware.Cors.Enabled, element.Middleware.Cors.Ignore, element.Middleware.Cors),
+			newMiddlewareInfo("jwt", element.Middleware.Jwt.Enabled, element.Middleware.Jwt.Ignore, redactJwt(element.Middleware.Jwt)),
+			newMiddlewareInfo("secure", element.Middleware.Secure.Enabled, element.Middleware.Secure.Ignore, element.Middleware.Secure),
+			newMiddlewareInfo("csrf", element.Middleware.Csrf.Enabled, element.Middleware.Csrf.Ignore, element.Middleware.Csrf),
+			newMiddlewareInfo("meta", element.Middleware.Meta.Enabled, element.Middleware.Meta.Ignore, element.Middleware.Meta),
+			newMiddlewareInfo("auth", element.Middleware.Auth.Enabled, element.Middleware.Auth.Ignore, redactAuth(element.Middleware.Auth)),
+			newMiddlewareInfo("rateLimit", element.Middleware.RateLimit.Enabled, element.Middleware.RateLimit.Ignore, element.Middleware.RateLimit),
+			newMiddlewareInfo("validation", element.Middleware.Validation.Enabled, element.Middleware.Validation.Ignore, element.Middleware.Validation),
+			newMiddlewareInfo("gcode", element.Middleware.Gcode.Enabled, element.Middleware.Gcode.Ignore, element.Middleware.Gcode),
+		}
+
 		entry := RegisterGfEntry(
 			WithLoggerEntry(loggerEntry),
 			WithEventEntry(eventEntry),
@@ -267,12 +458,34 @@ func RegisterGfEntryYAML(raw []byte) map[string]rkentry.Entry {
 			WithPromEntry(promEntry),
 			WithCommonServiceEntry(commonServiceEntry),
 			WithCertEntry(certEntry),
+			WithTlsClientAuth(clientAuth),
+			WithTlsCaEntry(caEntry),
+			WithTlsMinVersion(minVersion),
+			WithTlsCipherSuites(cipherSuites...),
+			WithTlsPemPath(element.TLS.CertPemPath, element.TLS.KeyPemPath),
+			WithPlaintextPort(element.Plaintext.Port, element.Plaintext.Mode),
+			WithManagementPort(element.Management.Port),
+			WithUnixSocket(element.Listener.Unix.Path, os.FileMode(unixSocketMode)),
+			WithSystemdSocket(element.Listener.Systemd),
+			WithReadTimeout(time.Duration(element.Server.ReadTimeoutMs)*time.Millisecond),
+			WithReadHeaderTimeout(time.Duration(element.Server.ReadHeaderTimeoutMs)*time.Millisecond),
+			WithWriteTimeout(time.Duration(element.Server.WriteTimeoutMs)*time.Millisecond),
+			WithIdleTimeout(time.Duration(element.Server.IdleTimeoutMs)*time.Millisecond),
+			WithMaxHeaderBytes(element.Server.MaxHeaderBytes),
+			WithMaxBodyBytes(element.Server.MaxBodyBytes),
+			withKeepAlive(element.Server.KeepAlive),
 			WithDocsEntry(docsEntry),
 			WithPProfEntry(pprofEntry),
 			WithStaticFileHandlerEntry(staticEntry),
+			WithErrorBuilder(errBuilder),
+			WithClients(clients...),
+			WithMeterProvider(meterProvider),
+			WithDrainTimeout(time.Duration(element.Shutdown.DrainTimeoutMs)*time.Millisecond),
+			WithPreStopDelay(time.Duration(element.Shutdown.PreStopDelayMs)*time.Millisecond),
 			WithMiddlewares(inters...))
 
 		entry.AddMiddleware(inters...)
+		entry.middlewareInfos = mids
 
 		res[name] = entry
 	}
@@ -289,14 +502,25 @@ func RegisterGfEntry(opts ...GfEntryOption) *GfEntry {
 		EventEntry:       rkentry.NewEventEntryStdout(),
 		Middlewares:      make([]ghttp.HandlerFunc, 0),
 		Port:             80,
+		fatalErrors:      make(chan error, 2),
+		clients:          make(map[string]*rkgfclient.Client),
 	}
 
 	for i := range opts {
 		opts[i](entry)
 	}
 
+	// port is not used by unix domain socket
+	if len(entry.unixSocketPath) > 0 && entry.preListener == nil && !entry.systemdSocket {
+		entry.Port = 0
+	}
+
 	if len(entry.entryName) < 1 {
 		entry.entryName = "gf-" + strconv.FormatUint(entry.Port, 10)
+		// entries with random port would share GoFrame server and overwrite each other in GlobalAppCtx
+		if entry.Port == 0 {
+			entry.entryName += "-" + strconv.FormatUint(atomic.AddUint64(&anonymousEntrySeq, 1), 10)
+		}
 	}
 
 	if entry.Server == nil {
@@ -310,6 +534,15 @@ func RegisterGfEntry(opts ...GfEntryOption) *GfEntry {
 		entry.Server.SetPort(int(entry.Port))
 	}
 
+	entry.serverConfig.apply(entry.Server)
+
+	// error builder is scoped to entry, rkmid.GetErrorBuilder() would be used if missing
+	rkgfinter.SetErrorBuilder(entry.entryName, entry.errorBuilder)
+
+	if entry.managementPort != 0 && entry.managementServer == nil {
+		entry.managementServer = entry.newManagementServer()
+	}
+
 	// add entry name and entry type into loki syncer if enabled
 	entry.LoggerEntry.AddEntryLabelToLokiSyncer(entry)
 	entry.EventEntry.AddEntryLabelToLokiSyncer(entry)
@@ -335,16 +568,36 @@ func (entry *GfEntry) GetDescription() string {
 }
 
 // Bootstrap GfEntry.
+//
+// Process would be shutdown with rkentry.ShutdownWithError if server failed to start.
 func (entry *GfEntry) Bootstrap(ctx context.Context) {
+	if err := entry.bootstrap(ctx); err != nil {
+		rkentry.ShutdownWithError(err)
+	}
+}
+
+// BootstrapWithError bootstrap GfEntry and returns *ServerError if server failed to start.
+//
+// Fatal errors occur while serving afterwards would be sent to FatalErrors() instead of exiting process.
+func (entry *GfEntry) BootstrapWithError(ctx context.Context) error {
+	entry.reportErrors = true
+	return entry.bootstrap(ctx)
+}
+
+func (entry *GfEntry) bootstrap(ctx context.Context) error {
 	event, logger := entry.logBasicInfo("Bootstrap", ctx)
 
+	// Common service, prom and pprof would be served by management server if management port provided
+	internal := entry.internalServer()
+
 	// Is common service enabled?
 	if entry.IsCommonServiceEnabled() {
 		// Register common service path into Router.
-		entry.Server.BindHandler(entry.CommonServiceEntry.ReadyPath, ghttp.WrapF(entry.CommonServiceEntry.Ready))
-		entry.Server.BindHandler(entry.CommonServiceEntry.GcPath, ghttp.WrapF(entry.CommonServiceEntry.Gc))
-		entry.Server.BindHandler(entry.CommonServiceEntry.InfoPath, ghttp.WrapF(entry.CommonServiceEntry.Info))
-		entry.Server.BindHandler(entry.CommonServiceEntry.AlivePath, ghttp.WrapF(entry.CommonServiceEntry.Alive))
+		internal.BindHandler(entry.CommonServiceEntry.ReadyPath, ghttp.WrapF(entry.ready))
+		internal.BindHandler(entry.CommonServiceEntry.GcPath, ghttp.WrapF(entry.CommonServiceEntry.Gc))
+		internal.BindHandler(entry.CommonServiceEntry.InfoPath, ghttp.WrapF(entry.CommonServiceEntry.Info))
+		internal.BindHandler(entry.CommonServiceEntry.AlivePath, ghttp.WrapF(entry.CommonServiceEntry.Alive))
+		internal.BindHandler(entry.routesPath(), ghttp.WrapF(entry.routes))
 
 		// Bootstrap common service entry.
 		entry.CommonServiceEntry.Bootstrap(ctx)
@@ -374,26 +627,30 @@ func (entry *GfEntry) Bootstrap(ctx context.Context) {
 	// Is prometheus enabled?
 	if entry.IsPromEnabled() {
 		// Register prom path into Router.
-		entry.Server.BindHandler(entry.PromEntry.Path, ghttp.WrapH(promhttp.HandlerFor(entry.PromEntry.Gatherer, promhttp.HandlerOpts{})))
+		internal.BindHandler(entry.PromEntry.Path, ghttp.WrapH(newPromHandler(entry.PromEntry)))
 		entry.PromEntry.Bootstrap(ctx)
 	}
 
 	// Is pprof enabled?
 	if entry.IsPProfEnabled() {
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path), ghttp.WrapF(pprof.Index))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "cmdline"), ghttp.WrapF(pprof.Cmdline))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "profile"), ghttp.WrapF(pprof.Profile))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "symbol"), ghttp.WrapF(pprof.Symbol))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "trace"), ghttp.WrapF(pprof.Trace))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "allocs"), ghttp.WrapF(pprof.Handler("allocs").ServeHTTP))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "block"), ghttp.WrapF(pprof.Handler("block").ServeHTTP))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "goroutine"), ghttp.WrapF(pprof.Handler("goroutine").ServeHTTP))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "heap"), ghttp.WrapF(pprof.Handler("heap").ServeHTTP))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "mutex"), ghttp.WrapF(pprof.Handler("mutex").ServeHTTP))
-		entry.Server.BindHandler(path.Join(entry.PProfEntry.Path, "threadcreate"), ghttp.WrapF(pprof.Handler("threadcreate").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path), ghttp.WrapF(pprof.Index))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "cmdline"), ghttp.WrapF(pprof.Cmdline))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "profile"), ghttp.WrapF(pprof.Profile))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "symbol"), ghttp.WrapF(pprof.Symbol))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "trace"), ghttp.WrapF(pprof.Trace))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "allocs"), ghttp.WrapF(pprof.Handler("allocs").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "block"), ghttp.WrapF(pprof.Handler("block").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "goroutine"), ghttp.WrapF(pprof.Handler("goroutine").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "heap"), ghttp.WrapF(pprof.Handler("heap").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "mutex"), ghttp.WrapF(pprof.Handler("mutex").ServeHTTP))
+		internal.BindHandler(path.Join(entry.PProfEntry.Path, "threadcreate"), ghttp.WrapF(pprof.Handler("threadcreate").ServeHTTP))
 	}
 
-	go entry.startServer(event, logger)
+	// Start server synchronously, so that address is available once Bootstrap returned
+	if err := entry.startServer(event, logger); err != nil {
+		entry.EventEntry.Finish(event)
+		return err
+	}
 
 	entry.bootstrapLogOnce.Do(func() {
 		// Print link and logging message
@@ -401,39 +658,50 @@ func (entry *GfEntry) Bootstrap(ctx context.Context) {
 		if entry.IsTlsEnabled() {
 			scheme = "https"
 		}
+		// management server is always plaintext
+		internalScheme := scheme
+		if entry.managementServer != nil {
+			internalScheme = "http"
+		}
 
 		if entry.IsSwEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("SwaggerEntry: %s://localhost:%d%s", scheme, entry.Port, entry.SwEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("SwaggerEntry: %s://%s%s", scheme, entry.listenHost(), entry.SwEntry.Path))
 		}
 		if entry.IsDocsEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("DocsEntry: %s://localhost:%d%s", scheme, entry.Port, entry.DocsEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("DocsEntry: %s://%s%s", scheme, entry.listenHost(), entry.DocsEntry.Path))
 		}
 		if entry.IsPromEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("PromEntry: %s://localhost:%d%s", scheme, entry.Port, entry.PromEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("PromEntry: %s://%s%s", internalScheme, entry.internalHost(), entry.PromEntry.Path))
 		}
 		if entry.IsStaticFileHandlerEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("StaticFileHandlerEntry: %s://localhost:%d%s", scheme, entry.Port, entry.StaticFileEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("StaticFileHandlerEntry: %s://%s%s", scheme, entry.listenHost(), entry.StaticFileEntry.Path))
 		}
 		if entry.IsCommonServiceEnabled() {
 			handlers := []string{
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.ReadyPath),
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.AlivePath),
-				fmt.Sprintf("%s://localhost:%d%s", scheme, entry.Port, entry.CommonServiceEntry.InfoPath),
+				fmt.Sprintf("%s://%s%s", internalScheme, entry.internalHost(), entry.CommonServiceEntry.ReadyPath),
+				fmt.Sprintf("%s://%s%s", internalScheme, entry.internalHost(), entry.CommonServiceEntry.AlivePath),
+				fmt.Sprintf("%s://%s%s", internalScheme, entry.internalHost(), entry.CommonServiceEntry.InfoPath),
+				fmt.Sprintf("%s://%s%s", internalScheme, entry.internalHost(), entry.routesPath()),
 			}
 
 			entry.LoggerEntry.Info(fmt.Sprintf("CommonSreviceEntry: %s", strings.Join(handlers, ", ")))
 		}
 		if entry.IsPProfEnabled() {
-			entry.LoggerEntry.Info(fmt.Sprintf("PProfEntry: %s://localhost:%d%s", scheme, entry.Port, entry.PProfEntry.Path))
+			entry.LoggerEntry.Info(fmt.Sprintf("PProfEntry: %s://%s%s", internalScheme, entry.internalHost(), entry.PProfEntry.Path))
 		}
 		entry.EventEntry.Finish(event)
 	})
+
+	return nil
 }
 
 // Interrupt GfEntry.
 func (entry *GfEntry) Interrupt(ctx context.Context) {
 	event, logger := entry.logBasicInfo("Interrupt", ctx)
 
+	// Drain in-flight requests before any sub entries get interrupted
+	entry.drain(event, logger)
+
 	if entry.IsSwEnabled() {
 		// Interrupt swagger entry
 		entry.SwEntry.Interrupt(ctx)
@@ -470,6 +738,43 @@ func (entry *GfEntry) Interrupt(ctx context.Context) {
 		}
 	}
 
+	if entry.managementServer != nil {
+		if err := entry.managementServer.Shutdown(); err != nil {
+			event.AddErr(err)
+			logger.Warn("Error occurs while stopping management server.", event.ListPayloads()...)
+		}
+	}
+
+	if entry.plaintextServer != nil {
+		if err := entry.plaintextServer.Close(); err != nil {
+			event.AddErr(err)
+			logger.Warn("Error occurs while stopping plaintext server.", event.ListPayloads()...)
+		}
+	}
+
+	if entry.certReloader != nil {
+		entry.certReloader.close()
+	}
+
+	// flush metrics of OpenTelemetry metrics middleware
+	if entry.meterProvider != nil {
+		if err := entry.meterProvider.Shutdown(ctx); err != nil {
+			event.AddErr(err)
+			logger.Warn("Error occurs while shutting down meter provider.", event.ListPayloads()...)
+		}
+	}
+
+	// socket file is removed while closing listener, make sure it is removed in case listener was never closed
+	if len(entry.unixSocketPath) > 0 && entry.preListener == nil && !entry.systemdSocket {
+		if err := os.Remove(entry.unixSocketPath); err != nil && !os.IsNotExist(err) {
+			event.AddErr(err)
+			logger.Warn("Error occurs while removing unix socket file.", event.ListPayloads()...)
+		}
+	}
+
+	// error builder of entry is stored globally by entry name
+	rkgfinter.SetErrorBuilder(entry.entryName, nil)
+
 	rkentry.GlobalAppCtx.RemoveEntry(entry)
 
 	entry.EventEntry.Finish(event)
@@ -490,6 +795,11 @@ func (entry *GfEntry) MarshalJSON() ([]byte, error) {
 		"type":                   entry.entryType,
 		"description":            entry.entryDescription,
 		"port":                   entry.Port,
+		"plaintextPort":          entry.plaintextPort,
+		"managementPort":         entry.managementPort,
+		"unixSocket":             entry.unixSocketPath,
+		"server":                 entry.serverConfig.toMap(),
+		"middlewares":            entry.middlewareInfos,
 		"swEntry":                entry.SwEntry,
 		"docsEntry":              entry.DocsEntry,
 		"commonServiceEntry":     entry.CommonServiceEntry,
@@ -529,6 +839,28 @@ func (entry *GfEntry) AddMiddleware(inters ...ghttp.HandlerFunc) {
 	entry.Server.Use(inters...)
 }
 
+// FatalErrors returns channel of *ServerError which occurs while serving,
+// errors would be sent only if entry was bootstrapped with BootstrapWithError().
+func (entry *GfEntry) FatalErrors() <-chan error {
+	return entry.fatalErrors
+}
+
+// GetAddr Get address server listening on, nil would be returned before Bootstrap() called.
+//
+// Useful while port is 0 and random port was picked by OS.
+func (entry *GfEntry) GetAddr() net.Addr {
+	if entry.listener == nil {
+		return nil
+	}
+
+	return entry.listener.Listener.Addr()
+}
+
+// IsDraining Is entry draining in-flight requests before shutdown?
+func (entry *GfEntry) IsDraining() bool {
+	return atomic.LoadInt32(&entry.draining) == 1
+}
+
 // IsTlsEnabled Is TLS enabled?
 func (entry *GfEntry) IsTlsEnabled() bool {
 	return entry.CertEntry != nil && entry.CertEntry.Certificate != nil
@@ -564,8 +896,70 @@ func (entry *GfEntry) IsPProfEnabled() bool {
 	return entry.PProfEntry != nil
 }
 
+// GetPromRegistry returns prometheus registry served by PromEntry, custom metrics could be registered
+// alongside metrics of middlewares. nil would be returned if PromEntry is disabled.
+func (entry *GfEntry) GetPromRegistry() *prometheus.Registry {
+	if entry.PromEntry == nil {
+		return nil
+	}
+
+	return entry.PromEntry.Registry
+}
+
+// GetClient returns outbound client declared with name, nil would be returned if missing.
+func (entry *GfEntry) GetClient(name string) *rkgfclient.Client {
+	return entry.clients[name]
+}
+
+// RegisterRedisAdapterFunc wraps redis adapters created by adapterFunc with rkgfredis.RegisterAdapterFunc(),
+// commands are labeled with name of entry and measured into prom registry of entry if PromEntry is enabled.
+//
+// Options provided by caller would override options of entry.
+func (entry *GfEntry) RegisterRedisAdapterFunc(adapterFunc gredis.AdapterFunc, opts ...rkgfredis.Option) {
+	entryOpts := []rkgfredis.Option{rkgfredis.WithEntryNameAndType(entry.GetName(), entry.GetType())}
+	if entry.PromEntry != nil {
+		entryOpts = append(entryOpts, rkgfredis.WithRegisterer(entry.PromEntry.Registerer))
+	}
+
+	rkgfredis.RegisterAdapterFunc(adapterFunc, append(entryOpts, opts...)...)
+}
+
+// RegisterGdbDriver wraps driver with rkgfgdb.Register() and registers it into gdb with name,
+// SQL statements are labeled with name of entry, logged with logger of entry if request logger is missing,
+// and measured into prom registry of entry if PromEntry is enabled.
+//
+// Options provided by caller would override options of entry.
+func (entry *GfEntry) RegisterGdbDriver(name string, driver gdb.Driver, opts ...rkgfgdb.Option) error {
+	entryOpts := []rkgfgdb.Option{
+		rkgfgdb.WithEntryNameAndType(entry.GetName(), entry.GetType()),
+		rkgfgdb.WithLogger(entry.LoggerEntry.Logger),
+	}
+	if entry.PromEntry != nil {
+		entryOpts = append(entryOpts, rkgfgdb.WithRegisterer(entry.PromEntry.Registerer))
+	}
+
+	return rkgfgdb.Register(name, driver, append(entryOpts, opts...)...)
+}
+
 // ***************** Helper function *****************
 
+// validateClients checks whether names of clients are provided and unique, since clients are retrieved by name.
+func validateClients(configs []rkgfclient.BootConfig) error {
+	names := make(map[string]bool)
+	for i := range configs {
+		name := configs[i].Name
+		if len(name) < 1 {
+			return fmt.Errorf("clients[%d].name is required", i)
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate name of clients:%s", name)
+		}
+		names[name] = true
+	}
+
+	return nil
+}
+
 // Add basic fields into event.
 func (entry *GfEntry) logBasicInfo(operation string, ctx context.Context) (rkquery.Event, *zap.Logger) {
 	event := entry.EventEntry.Start(
@@ -587,7 +981,8 @@ func (entry *GfEntry) logBasicInfo(operation string, ctx context.Context) (rkque
 
 	// add general info
 	event.AddPayloads(
-		zap.Uint64("gfPort", entry.Port))
+		zap.Uint64("gfPort", entry.Port),
+		zap.Any("server", entry.serverConfig.toMap()))
 
 	// add SwEntry info
 	if entry.IsSwEnabled() {
@@ -621,7 +1016,7 @@ func (entry *GfEntry) logBasicInfo(operation string, ctx context.Context) (rkque
 	if entry.IsPromEnabled() {
 		event.AddPayloads(
 			zap.Bool("promEnabled", true),
-			zap.Uint64("promPort", entry.Port),
+			zap.Uint64("promPort", entry.internalPort()),
 			zap.String("promPath", entry.PromEntry.Path))
 	}
 
@@ -632,10 +1027,34 @@ func (entry *GfEntry) logBasicInfo(operation string, ctx context.Context) (rkque
 			zap.String("staticFileHandlerPath", entry.StaticFileEntry.Path))
 	}
 
+	// add listener info
+	if len(entry.unixSocketPath) > 0 {
+		event.AddPayloads(
+			zap.String("unixSocket", entry.unixSocketPath))
+	}
+	if entry.systemdSocket {
+		event.AddPayloads(
+			zap.Bool("systemdSocket", true))
+	}
+
+	// add management server info
+	if entry.managementPort != 0 {
+		event.AddPayloads(
+			zap.Uint64("managementPort", entry.managementPort))
+	}
+
+	// add plaintext server info
+	if entry.plaintextPort != 0 {
+		event.AddPayloads(
+			zap.Uint64("plaintextPort", entry.plaintextPort),
+			zap.String("plaintextMode", entry.plaintextMode))
+	}
+
 	// add tls info
 	if entry.IsTlsEnabled() {
 		event.AddPayloads(
-			zap.Bool("tlsEnabled", true))
+			zap.Bool("tlsEnabled", true),
+			zap.String("tlsClientAuth", entry.tlsClientAuth.String()))
 	}
 
 	logger.Info(fmt.Sprintf("%s gfEntry", operation))
@@ -645,20 +1064,165 @@ func (entry *GfEntry) logBasicInfo(operation string, ctx context.Context) (rkque
 
 // Start server
 // We move the code here for testability
-func (entry *GfEntry) startServer(event rkquery.Event, logger *zap.Logger) {
-	if entry.Server != nil {
-		// If TLS was enabled, we need to load server certificate and key and start http server with ListenAndServeTLS()
-		if entry.IsTlsEnabled() {
-			entry.Server.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{*entry.CertEntry.Certificate}})
-		}
+func (entry *GfEntry) startServer(event rkquery.Event, logger *zap.Logger) error {
+	if entry.Server == nil {
+		return nil
+	}
 
-		err := entry.Server.Start()
+	// Listen by ourselves, so that new connections could be refused while draining
+	raw, err := entry.listen()
+	if err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while listening.", event.ListPayloads()...)
+		return entry.newServerError(ServerErrorOpListen, err)
+	}
+
+	// Port might be picked by OS, replace it with the real one, port is not used by unix domain socket
+	if addr, ok := raw.Addr().(*net.TCPAddr); ok {
+		entry.Port = uint64(addr.Port)
+	} else {
+		entry.Port = 0
+	}
 
-		if err != nil && err != http.ErrServerClosed {
+	entry.listener = &drainListener{
+		Listener:      raw,
+		onAcceptErr:   entry.onAcceptErr,
+		headerTimeout: entry.serverConfig.readHeaderTimeout,
+	}
+	var ln net.Listener = entry.listener
+
+	if entry.IsTlsEnabled() {
+		// Serve certificate with reloader, so that rotated certificate takes effect without restart
+		entry.certReloader = newCertReloader(entry)
+		if err := entry.certReloader.verify(); err != nil {
 			event.AddErr(err)
-			logger.Error("Error occurs while starting GoFrame server.", event.ListPayloads()...)
-			rkentry.ShutdownWithError(err)
+			logger.Error("Error occurs while verifying certificate files.", event.ListPayloads()...)
+			entry.closeListeners()
+			return entry.newServerError(ServerErrorOpTls, err)
+		}
+		if err := entry.certReloader.watch(); err != nil {
+			event.AddErr(err)
+			logger.Warn("Error occurs while watching certificate files, rotation disabled.", event.ListPayloads()...)
+		}
+
+		conf, err := entry.newTlsConfig()
+		if err != nil {
+			event.AddErr(err)
+			logger.Error("Error occurs while building TLS config.", event.ListPayloads()...)
+			entry.closeListeners()
+			return entry.newServerError(ServerErrorOpTls, err)
 		}
+
+		// ghttp.Server ignores GetCertificate if no certificate provided,
+		// so we terminate TLS in listener
+		ln = tls.NewListener(ln, conf)
+	}
+
+	if err := entry.Server.SetListener(ln); err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while setting listener.", event.ListPayloads()...)
+		entry.closeListeners()
+		return entry.newServerError(ServerErrorOpListen, err)
+	}
+
+	// Count in-flight requests, so that we know how many of them are aborted by shutdown
+	entry.Server.SetHandler(entry.serveHTTP)
+
+	if err := entry.Server.Start(); err != nil && err != http.ErrServerClosed {
+		event.AddErr(err)
+		logger.Error("Error occurs while starting GoFrame server.", event.ListPayloads()...)
+		entry.closeListeners()
+		return entry.newServerError(ServerErrorOpStart, err)
+	}
+
+	if err := entry.startManagementServer(event, logger); err != nil {
+		entry.Server.Shutdown()
+		entry.closeListeners()
+		return err
+	}
+
+	if err := entry.startPlaintextServer(event, logger); err != nil {
+		entry.Server.Shutdown()
+		if entry.managementServer != nil {
+			entry.managementServer.Shutdown()
+		}
+		entry.closeListeners()
+		return err
+	}
+
+	return nil
+}
+
+// closeListeners closes listener and certificate watcher opened while starting server.
+func (entry *GfEntry) closeListeners() {
+	if entry.listener != nil {
+		entry.listener.Close()
+	}
+
+	if entry.certReloader != nil {
+		entry.certReloader.close()
+	}
+}
+
+// serveHTTP wraps ghttp.Server.ServeHTTP with in-flight request counting.
+func (entry *GfEntry) serveHTTP(writer http.ResponseWriter, req *http.Request) {
+	atomic.AddInt64(&entry.inFlight, 1)
+	defer atomic.AddInt64(&entry.inFlight, -1)
+
+	// Request header was read, stop timer of read header timeout
+	if entry.listener != nil {
+		defer entry.listener.startRequest(req.RemoteAddr)()
+	}
+
+	// Ask client to close keep-alive connection while draining
+	if entry.IsDraining() {
+		writer.Header().Set("Connection", "close")
+	}
+
+	entry.Server.ServeHTTP(writer, req)
+}
+
+// ready wraps rkentry.CommonServiceEntry.Ready, returns 503 while draining.
+func (entry *GfEntry) ready(writer http.ResponseWriter, req *http.Request) {
+	if entry.IsDraining() {
+		resp := rkgfinter.GetErrorBuilder(entry.entryName).New(http.StatusServiceUnavailable, "Server is draining")
+		if problem, ok := resp.(*rkgfinter.ErrorProblem); ok {
+			resp = problem.WithRequest(req, writer.Header())
+		}
+		writer.Header().Set(rkmid.HeaderContentType, rkgfinter.ErrorContentType(resp))
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		bytes, _ := json.Marshal(resp)
+		writer.Write(bytes)
+		return
+	}
+
+	entry.CommonServiceEntry.Ready(writer, req)
+}
+
+// drain marks entry as draining, refuses new connections and waits for in-flight requests
+// to finish until drain timeout. Requests still running at the deadline would be aborted
+// by Server.Shutdown(), and the number of them is recorded in event.
+func (entry *GfEntry) drain(event rkquery.Event, logger *zap.Logger) {
+	atomic.StoreInt32(&entry.draining, 1)
+
+	// wait for load balancer to notice readiness change before refusing new connections
+	if entry.preStopDelay > 0 {
+		time.Sleep(entry.preStopDelay)
+	}
+
+	if entry.listener != nil {
+		entry.listener.drain()
+	}
+
+	deadline := time.Now().Add(entry.drainTimeout)
+	for atomic.LoadInt64(&entry.inFlight) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	aborted := atomic.LoadInt64(&entry.inFlight)
+	event.SetCounter("abortedRequests", aborted)
+	if aborted > 0 {
+		logger.Warn(fmt.Sprintf("Aborting %d in-flight requests after drain timeout.", aborted))
 	}
 }
 
@@ -667,13 +1231,35 @@ func (entry *GfEntry) startServer(event rkquery.Event, logger *zap.Logger) {
 // GfEntryOption Gf entry option.
 type GfEntryOption func(*GfEntry)
 
-// WithPort provide port.
+// WithPort provide port, random port would be picked if 0.
 func WithPort(port uint64) GfEntryOption {
 	return func(entry *GfEntry) {
 		entry.Port = port
 	}
 }
 
+// WithListener provide pre-opened listener, Port would be ignored while listening.
+func WithListener(ln net.Listener) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.preListener = ln
+	}
+}
+
+// WithUnixSocket provide unix domain socket path and file mode, Port would be ignored while listening.
+func WithUnixSocket(path string, mode os.FileMode) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.unixSocketPath = path
+		entry.unixSocketMode = mode
+	}
+}
+
+// WithSystemdSocket listen on socket passed by systemd socket activation with LISTEN_FDS.
+func WithSystemdSocket(enabled bool) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.systemdSocket = enabled
+	}
+}
+
 // WithName provide name.
 func WithName(name string) GfEntryOption {
 	return func(entry *GfEntry) {
@@ -709,6 +1295,62 @@ func WithCertEntry(certEntry *rkentry.CertEntry) GfEntryOption {
 	}
 }
 
+// WithTlsClientAuth provide policy of client certificate verification.
+func WithTlsClientAuth(clientAuth tls.ClientAuthType) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.tlsClientAuth = clientAuth
+	}
+}
+
+// WithTlsCaEntry provide rkentry.CertEntry whose CA verifies client certificates.
+func WithTlsCaEntry(caEntry *rkentry.CertEntry) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.tlsCaEntry = caEntry
+	}
+}
+
+// WithTlsMinVersion provide minimum TLS version, like tls.VersionTLS12.
+func WithTlsMinVersion(version uint16) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.tlsMinVersion = version
+	}
+}
+
+// WithTlsCipherSuites provide enabled cipher suites for TLS 1.0-1.2.
+func WithTlsCipherSuites(suites ...uint16) GfEntryOption {
+	return func(entry *GfEntry) {
+		if len(suites) > 0 {
+			entry.tlsCipherSuites = suites
+		}
+	}
+}
+
+// WithTlsPemPath provide local cert and key files of CertEntry, rotated certificate would be reloaded once they changed.
+func WithTlsPemPath(certPemPath, keyPemPath string) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.tlsCertPemPath = certPemPath
+		entry.tlsKeyPemPath = keyPemPath
+	}
+}
+
+// WithPlaintextPort provide second plaintext port, mode could be one of PlaintextModeRedirect and PlaintextModeInternal.
+func WithPlaintextPort(port uint64, mode string) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.plaintextPort = port
+		entry.plaintextMode = strings.ToLower(mode)
+		if len(entry.plaintextMode) < 1 {
+			entry.plaintextMode = PlaintextModeRedirect
+		}
+	}
+}
+
+// WithManagementPort provide port of management server which serves common service, prom and pprof endpoints.
+func WithManagementPort(port uint64) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.managementPort = port
+	}
+}
+
 // WithSwEntry provide SwEntry.
 func WithSwEntry(sw *rkentry.SWEntry) GfEntryOption {
 	return func(entry *GfEntry) {
@@ -755,6 +1397,116 @@ func WithDocsEntry(docsEntry *rkentry.DocsEntry) GfEntryOption {
 	}
 }
 
+// WithErrorBuilder provide rkerror.ErrorBuilder of entry, error responses of middlewares would be built with it.
+func WithErrorBuilder(builder rkerror.ErrorBuilder) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.errorBuilder = builder
+	}
+}
+
+// WithClients provide outbound clients which could be retrieved with GetClient() by name, name of clients should be unique.
+func WithClients(clients ...*rkgfclient.Client) GfEntryOption {
+	return func(entry *GfEntry) {
+		for i := range clients {
+			if clients[i] == nil {
+				continue
+			}
+			if _, ok := entry.clients[clients[i].GetName()]; ok {
+				rkentry.ShutdownWithError(fmt.Errorf("duplicate name of clients:%s", clients[i].GetName()))
+			}
+			entry.clients[clients[i].GetName()] = clients[i]
+		}
+	}
+}
+
+// WithMeterProvider provide MeterProvider of OpenTelemetry metrics middleware, which would be shut down
+// while interrupting entry.
+func WithMeterProvider(provider *sdkmetric.MeterProvider) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.meterProvider = provider
+	}
+}
+
+// WithDrainTimeout provide max duration to wait for in-flight requests while shutting down.
+func WithDrainTimeout(timeout time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.drainTimeout = timeout
+	}
+}
+
+// WithReadTimeout provide max duration for reading entire request, value of ghttp.Server would be kept if 0.
+func WithReadTimeout(timeout time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		if timeout > 0 {
+			entry.serverConfig.readTimeout = timeout
+		}
+	}
+}
+
+// WithReadHeaderTimeout provide max duration for reading request header, disabled if 0.
+func WithReadHeaderTimeout(timeout time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		if timeout > 0 {
+			entry.serverConfig.readHeaderTimeout = timeout
+		}
+	}
+}
+
+// WithWriteTimeout provide max duration before timing out writes of response, value of ghttp.Server would be kept if 0.
+func WithWriteTimeout(timeout time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		if timeout > 0 {
+			entry.serverConfig.writeTimeout = timeout
+		}
+	}
+}
+
+// WithIdleTimeout provide max duration to wait for next request with keep-alive, value of ghttp.Server would be kept if 0.
+func WithIdleTimeout(timeout time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		if timeout > 0 {
+			entry.serverConfig.idleTimeout = timeout
+		}
+	}
+}
+
+// WithMaxHeaderBytes provide max bytes of request header, value of ghttp.Server would be kept if 0.
+func WithMaxHeaderBytes(size int) GfEntryOption {
+	return func(entry *GfEntry) {
+		if size > 0 {
+			entry.serverConfig.maxHeaderBytes = size
+		}
+	}
+}
+
+// WithMaxBodyBytes provide max bytes of request body, value of ghttp.Server would be kept if 0.
+func WithMaxBodyBytes(size int64) GfEntryOption {
+	return func(entry *GfEntry) {
+		if size > 0 {
+			entry.serverConfig.maxBodyBytes = size
+		}
+	}
+}
+
+// WithKeepAlive enable or disable HTTP keep-alive, enabled by default.
+func WithKeepAlive(enabled bool) GfEntryOption {
+	return withKeepAlive(&enabled)
+}
+
+// withKeepAlive provide keep-alive from boot config, keep-alive of ghttp.Server would be kept if nil.
+func withKeepAlive(enabled *bool) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.serverConfig.keepAlive = enabled
+	}
+}
+
+// WithPreStopDelay provide duration to keep serving with readiness failed before refusing new connections.
+func WithPreStopDelay(delay time.Duration) GfEntryOption {
+	return func(entry *GfEntry) {
+		entry.preStopDelay = delay
+	}
+}
+
 // WithPProfEntry provide rkentry.PProfEntry.
 func WithPProfEntry(p *rkentry.PProfEntry) GfEntryOption {
 	return func(entry *GfEntry) {
@@ -7,20 +7,47 @@ package rkgf
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"embed"
+	"encoding/json"
 	"encoding/pem"
+	"errors"
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/client"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
 	"github.com/rookie-ninja/rk-gf/middleware/meta"
+	"github.com/rookie-ninja/rk-gf/middleware/otelmetrics"
+	"github.com/rookie-ninja/rk-gf/middleware/prom"
 	"github.com/stretchr/testify/assert"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"io"
 	"math/big"
 	"net"
+	"net/http"
+	"os"
+	"path"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -102,6 +129,36 @@ func TestGetGfEntry(t *testing.T) {
 	rkentry.GlobalAppCtx.RemoveEntry(gfEntry)
 }
 
+func TestRegisterGfEntry_WithRandomPort(t *testing.T) {
+	entries := []*GfEntry{
+		RegisterGfEntry(WithPort(0), WithLoggerEntry(rkentry.LoggerEntryNoop), WithEventEntry(rkentry.EventEntryNoop)),
+		RegisterGfEntry(WithPort(0), WithLoggerEntry(rkentry.LoggerEntryNoop), WithEventEntry(rkentry.EventEntryNoop)),
+	}
+	assert.NotEqual(t, entries[0].GetName(), entries[1].GetName())
+	assert.NotSame(t, entries[0].Server, entries[1].Server)
+
+	wg := &sync.WaitGroup{}
+	for i := range entries {
+		wg.Add(1)
+		go func(entry *GfEntry) {
+			defer wg.Done()
+			assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+		}(entries[i])
+	}
+	wg.Wait()
+
+	assert.NotNil(t, entries[0].GetAddr())
+	assert.NotNil(t, entries[1].GetAddr())
+	assert.NotEqual(t, entries[0].GetAddr().String(), entries[1].GetAddr().String())
+	assert.Equal(t, entries[0], rkentry.GlobalAppCtx.GetEntry(GfEntryType, entries[0].GetName()))
+	assert.Equal(t, entries[1], rkentry.GlobalAppCtx.GetEntry(GfEntryType, entries[1].GetName()))
+
+	for i := range entries {
+		entries[i].Interrupt(context.TODO())
+		rkentry.GlobalAppCtx.RemoveEntry(entries[i])
+	}
+}
+
 func TestRegisterGfEntry(t *testing.T) {
 	// without options
 	entry := RegisterGfEntry()
@@ -215,6 +272,352 @@ func TestGinEntry_Bootstrap(t *testing.T) {
 	entry.Interrupt(context.TODO())
 }
 
+func TestGfEntry_Interrupt_Drain(t *testing.T) {
+	commonServiceEntry := rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+		Enabled: true,
+	})
+
+	entry := RegisterGfEntry(
+		WithName("ut-drain"),
+		WithPort(8082),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCommonServiceEntry(commonServiceEntry),
+		WithPreStopDelay(500*time.Millisecond),
+		WithDrainTimeout(2*time.Second))
+	entry.Server.BindHandler("/slow", func(ctx *ghttp.Request) {
+		time.Sleep(time.Second)
+		ctx.Response.WriteStatus(http.StatusOK)
+	})
+	entry.Bootstrap(context.TODO())
+	validateServerIsUp(t, 8082, false)
+
+	// in-flight request should be finished
+	slowCode := make(chan int)
+	go func() {
+		resp, err := http.Get("http://127.0.0.1:8082/slow")
+		if err != nil {
+			slowCode <- 0
+			return
+		}
+		resp.Body.Close()
+		slowCode <- resp.StatusCode
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	interrupted := make(chan struct{})
+	go func() {
+		entry.Interrupt(context.TODO())
+		close(interrupted)
+	}()
+
+	// readiness should fail during pre-stop delay
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, entry.IsDraining())
+	resp, err := http.Get("http://127.0.0.1:8082" + commonServiceEntry.ReadyPath)
+	assert.Nil(t, err)
+	if resp != nil {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, http.StatusOK, <-slowCode)
+	<-interrupted
+	assert.Zero(t, atomic.LoadInt64(&entry.inFlight))
+}
+
+func TestGfEntry_MutualTls(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := generateCA(t, dir)
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+	clientCert := generateSignedCert(t, dir, "client", caCert, caKey, x509.ExtKeyUsageClientAuth)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-mtls",
+				CAPath:      path.Join(dir, "ca.pem"),
+				CertPemPath: path.Join(dir, "server.pem"),
+				KeyPemPath:  path.Join(dir, "server-key.pem"),
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	entry := RegisterGfEntry(
+		WithName("ut-mtls"),
+		WithPort(8083),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCertEntry(certEntry),
+		WithTlsClientAuth(tls.RequireAndVerifyClientCert),
+		WithTlsMinVersion(tls.VersionTLS12))
+	entry.Server.BindHandler("/whoami", func(ctx *ghttp.Request) {
+		ctx.Response.Write(rkgfctx.GetClientCertSubject(ctx) + "|" + strings.Join(rkgfctx.GetClientCertSANs(ctx), ","))
+	})
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+	time.Sleep(time.Second)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+
+	// without client certificate
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	_, err := client.Get("https://127.0.0.1:8083/whoami")
+	assert.NotNil(t, err)
+
+	// with client certificate signed by CA
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	resp, err := client.Get("https://127.0.0.1:8083/whoami")
+	assert.Nil(t, err)
+	if resp != nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "CN=client|client.rk", string(body))
+	}
+}
+
+func TestGfEntry_CertRotation(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := generateCA(t, dir)
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-rotate",
+				CertPemPath: path.Join(dir, "server.pem"),
+				KeyPemPath:  path.Join(dir, "server-key.pem"),
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	promEntry := rkentry.RegisterPromEntry(&rkentry.BootProm{
+		Enabled: true,
+	}, rkentry.WithRegistryPromEntry(prometheus.NewRegistry()))
+
+	entry := RegisterGfEntry(
+		WithName("ut-rotate"),
+		WithPort(8084),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCertEntry(certEntry),
+		WithTlsPemPath(path.Join(dir, "server.pem"), path.Join(dir, "server-key.pem")),
+		WithPromEntry(promEntry))
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+	validateServerIsUp(t, 8084, true)
+
+	getServing := func() *x509.Certificate {
+		conn, err := tls.Dial("tcp", "127.0.0.1:8084", &tls.Config{InsecureSkipVerify: true})
+		assert.Nil(t, err)
+		if conn == nil {
+			return nil
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0]
+	}
+
+	before := getServing()
+	assert.NotNil(t, before)
+
+	// expiry gauge should be registered into prom registry of entry
+	families, err := promEntry.Gatherer.Gather()
+	assert.Nil(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() == "rk_gf_tls_cert_expiry_timestamp_seconds" {
+			found = true
+			assert.Equal(t, float64(before.NotAfter.Unix()), family.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	assert.True(t, found)
+
+	// rotate certificate
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+	time.Sleep(time.Second)
+
+	after := getServing()
+	assert.NotNil(t, after)
+	if before != nil && after != nil {
+		assert.NotEqual(t, before.SerialNumber, after.SerialNumber)
+	}
+}
+
+func TestGfEntry_CertRotation_WithMismatchedPemPath(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := generateCA(t, dir)
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+	generateSignedCert(t, dir, "other", caCert, caKey, x509.ExtKeyUsageServerAuth)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-rotate-mismatch",
+				CertPemPath: path.Join(dir, "server.pem"),
+				KeyPemPath:  path.Join(dir, "server-key.pem"),
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	entry := RegisterGfEntry(
+		WithName("ut-rotate-mismatch"),
+		WithPort(0),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCertEntry(certEntry),
+		WithTlsPemPath(path.Join(dir, "other.pem"), path.Join(dir, "other-key.pem")))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	err := entry.BootstrapWithError(context.TODO())
+	var serverErr *ServerError
+	assert.True(t, errors.As(err, &serverErr))
+	assert.Equal(t, ServerErrorOpTls, serverErr.Op)
+}
+
+func TestCertReloader_WithEmbedFS(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := generateCA(t, dir)
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-rotate-embed",
+				CertPemPath: path.Join(dir, "server.pem"),
+				KeyPemPath:  path.Join(dir, "server-key.pem"),
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	entry := RegisterGfEntry(
+		WithName("ut-rotate-embed"),
+		WithCertEntry(certEntry),
+		WithTlsPemPath(path.Join(dir, "server.pem"), path.Join(dir, "server-key.pem")))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	// local files are watched
+	reloader := newCertReloader(entry)
+	assert.Nil(t, reloader.watch())
+	assert.NotNil(t, reloader.watcher)
+	reloader.close()
+
+	// embed.FS is immutable, watcher is skipped
+	rkentry.GlobalAppCtx.AddEmbedFS(rkentry.CertEntryType, certEntry.GetName(), &embed.FS{})
+	reloader = newCertReloader(entry)
+	assert.Nil(t, reloader.watch())
+	assert.Nil(t, reloader.watcher)
+}
+
+func TestGfEntry_Plaintext(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := generateCA(t, dir)
+	generateSignedCert(t, dir, "server", caCert, caKey, x509.ExtKeyUsageServerAuth)
+
+	certEntry := rkentry.RegisterCertEntry(&rkentry.BootCert{
+		Cert: []*rkentry.BootCertE{
+			{
+				Name:        "ut-plaintext",
+				CertPemPath: path.Join(dir, "server.pem"),
+				KeyPemPath:  path.Join(dir, "server-key.pem"),
+			},
+		},
+	})[0]
+	certEntry.Bootstrap(context.TODO())
+	defer rkentry.GlobalAppCtx.RemoveEntry(certEntry)
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// redirect mode
+	entry := RegisterGfEntry(
+		WithName("ut-plaintext-redirect"),
+		WithPort(8085),
+		WithPlaintextPort(8086, ""),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCertEntry(certEntry))
+	entry.Bootstrap(context.TODO())
+	validateServerIsUp(t, 8086, false)
+
+	resp, err := client.Get("http://127.0.0.1:8086/ut?key=value")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+		assert.Equal(t, "https://127.0.0.1:8085/ut?key=value", resp.Header.Get("Location"))
+	}
+	entry.Interrupt(context.TODO())
+
+	// internal mode
+	commonServiceEntry := rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+		Enabled: true,
+	})
+	entry = RegisterGfEntry(
+		WithName("ut-plaintext-internal"),
+		WithPort(8087),
+		WithPlaintextPort(8088, PlaintextModeInternal),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCommonServiceEntry(commonServiceEntry),
+		WithCertEntry(certEntry))
+	entry.Bootstrap(context.TODO())
+	validateServerIsUp(t, 8088, false)
+
+	resp, err = client.Get("http://127.0.0.1:8088" + commonServiceEntry.ReadyPath)
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = client.Get("http://127.0.0.1:8088/ut")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+	entry.Interrupt(context.TODO())
+}
+
+func TestParseTlsOptions(t *testing.T) {
+	clientAuth, err := parseClientAuth("requireAndVerify")
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, clientAuth)
+	_, err = parseClientAuth("invalid")
+	assert.NotNil(t, err)
+
+	version, err := parseTlsVersion("1.3")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+	_, err = parseTlsVersion("2.0")
+	assert.NotNil(t, err)
+
+	suites, err := parseCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	assert.Nil(t, err)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, suites)
+	_, err = parseCipherSuites("invalid")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, (&BootTLS{}).validatePemPath())
+	assert.Nil(t, (&BootTLS{CertPemPath: "ut-cert", KeyPemPath: "ut-key"}).validatePemPath())
+	assert.NotNil(t, (&BootTLS{CertPemPath: "ut-cert"}).validatePemPath())
+}
+
 func TestRegisterGfEntriesWithConfig(t *testing.T) {
 	// write config file in unit test temp directory
 	entries := RegisterGfEntryYAML([]byte(defaultBootConfigStr))
@@ -232,6 +635,632 @@ func TestRegisterGfEntriesWithConfig(t *testing.T) {
 	assert.Nil(t, greeter3)
 }
 
+func TestRegisterGfEntryYAML_ScopedMiddleware(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-scoped-amazon
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: amazon
+      ignore: ["/ut-ignored"]
+      auth:
+        enabled: true
+        apiKey: ["ut-key"]
+  - name: ut-scoped-google
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: google
+      auth:
+        enabled: true
+        apiKey: ["ut-key"]
+`
+	entries := RegisterGfEntryYAML([]byte(bootStr))
+	for _, name := range []string{"ut-scoped-amazon", "ut-scoped-google"} {
+		entry := entries[name].(*GfEntry)
+		entry.Server.BindHandler("/ut-ignored", func(ctx *ghttp.Request) {
+			ctx.Response.WriteStatus(http.StatusOK)
+		})
+		assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+		defer entry.Interrupt(context.TODO())
+	}
+
+	get := func(name, p string) (int, string) {
+		resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entries[name].(*GfEntry).Port, 10) + p)
+		assert.Nil(t, err)
+		if resp == nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	// error model of each entry
+	code, body := get("ut-scoped-amazon", "/ut")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Contains(t, body, `"response"`)
+	code, body = get("ut-scoped-google", "/ut")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Contains(t, body, `"error"`)
+	assert.NotContains(t, body, `"response"`)
+
+	// ignore list of each entry
+	code, _ = get("ut-scoped-amazon", "/ut-ignored")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = get("ut-scoped-google", "/ut-ignored")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestRegisterGfEntryYAML_ProblemErrorModel(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-problem
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: problem
+      meta:
+        enabled: true
+      auth:
+        enabled: true
+        apiKey: ["ut-key"]
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-problem"].(*GfEntry)
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/ut")
+	assert.Nil(t, err)
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&m))
+	assert.Equal(t, "about:blank", m["type"])
+	assert.Equal(t, "Unauthorized", m["title"])
+	assert.Equal(t, float64(http.StatusUnauthorized), m["status"])
+	assert.NotEmpty(t, m["detail"])
+	assert.Equal(t, "/ut", m["instance"])
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), m["requestId"])
+	assert.NotEmpty(t, m["requestId"])
+}
+
+func TestRegisterGfEntryYAML_ProblemErrorModelWithJwt(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-problem-jwt
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: problem
+      jwt:
+        enabled: true
+      meta:
+        enabled: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-problem-jwt"].(*GfEntry)
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/ut")
+	assert.Nil(t, err)
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// error is written by jwt middleware before meta middleware
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&m))
+	assert.NotEmpty(t, m["requestId"])
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), m["requestId"])
+}
+
+func TestGfEntry_Interrupt_WithErrorModel(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-interrupt-error-model
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: problem
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-interrupt-error-model"].(*GfEntry)
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	assert.IsType(t, rkgfinter.NewErrorBuilderProblem(), rkgfinter.GetErrorBuilder(entry.GetName()))
+
+	// error builder of entry is removed after interrupted
+	entry.Interrupt(context.TODO())
+	assert.Equal(t, rkmid.GetErrorBuilder(), rkgfinter.GetErrorBuilder(entry.GetName()))
+}
+
+func TestRegisterGfEntryYAML_InvalidErrorModel(t *testing.T) {
+	defer assertPanic(t)
+
+	bootStr := `
+gf:
+  - name: ut-invalid-error-model
+    port: 0
+    enabled: true
+    middleware:
+      errorModel: ut-invalid
+`
+	RegisterGfEntryYAML([]byte(bootStr))
+}
+
+func TestRegisterGfEntryYAML_Gcode(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-gcode
+    port: 0
+    enabled: true
+    middleware:
+      gcode:
+        enabled: true
+        mapping:
+          10001: 409
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-gcode"].(*GfEntry)
+	entry.Server.BindHandler("/ut-not-found", func(ctx *ghttp.Request) {
+		ctx.SetError(gerror.NewCode(gcode.CodeNotFound, "ut-not-found"))
+	})
+	entry.Server.BindHandler("/ut-conflict", func(ctx *ghttp.Request) {
+		ctx.SetError(gerror.NewCode(gcode.New(10001, "", nil), "ut-conflict"))
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	for urlPath, code := range map[string]int{
+		"/ut-not-found": http.StatusNotFound,
+		"/ut-conflict":  http.StatusConflict,
+	} {
+		resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + urlPath)
+		assert.Nil(t, err)
+		if resp == nil {
+			continue
+		}
+		resp.Body.Close()
+		assert.Equal(t, code, resp.StatusCode)
+	}
+}
+
+func TestRegisterGfEntryYAML_Clients(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-clients
+    port: 0
+    enabled: true
+    clients:
+      - name: ut-client
+        baseUrl: http://127.0.0.1:8080
+        timeoutMs: 1000
+        retry:
+          count: 2
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-clients"].(*GfEntry)
+	client := entry.GetClient("ut-client")
+	assert.NotNil(t, client)
+	assert.Equal(t, "ut-client", client.GetName())
+	assert.Equal(t, time.Second, client.GetClient().Client.Timeout)
+	assert.Nil(t, entry.GetClient("ut-missing"))
+}
+
+func TestValidateClients(t *testing.T) {
+	assert.Nil(t, validateClients(nil))
+	assert.Nil(t, validateClients([]rkgfclient.BootConfig{{Name: "ut-client"}, {Name: "ut-other"}}))
+	assert.NotNil(t, validateClients([]rkgfclient.BootConfig{{Name: "ut-client"}, {}}))
+	assert.NotNil(t, validateClients([]rkgfclient.BootConfig{{Name: "ut-client"}, {Name: "ut-client"}}))
+}
+
+func TestRegisterGfEntryYAML_ClientsWithDuplicateName(t *testing.T) {
+	defer assertPanic(t)
+
+	bootStr := `
+gf:
+  - name: ut-clients-duplicate
+    port: 0
+    enabled: true
+    clients:
+      - name: ut-client
+      - name: ut-client
+`
+	RegisterGfEntryYAML([]byte(bootStr))
+}
+
+func TestWithClients_WithDuplicateName(t *testing.T) {
+	defer assertPanic(t)
+
+	RegisterGfEntry(
+		WithName("ut-clients-duplicate"),
+		WithClients(rkgfclient.New(), rkgfclient.New()))
+}
+
+// fakeRedisAdapter replies OK to every command.
+type fakeRedisAdapter struct {
+	gredis.Adapter
+}
+
+func (a *fakeRedisAdapter) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return gvar.New("OK"), nil
+}
+
+func (a *fakeRedisAdapter) GroupGeneric() gredis.IGroupGeneric     { return nil }
+func (a *fakeRedisAdapter) GroupHash() gredis.IGroupHash           { return nil }
+func (a *fakeRedisAdapter) GroupList() gredis.IGroupList           { return nil }
+func (a *fakeRedisAdapter) GroupPubSub() gredis.IGroupPubSub       { return nil }
+func (a *fakeRedisAdapter) GroupScript() gredis.IGroupScript       { return nil }
+func (a *fakeRedisAdapter) GroupSet() gredis.IGroupSet             { return nil }
+func (a *fakeRedisAdapter) GroupSortedSet() gredis.IGroupSortedSet { return nil }
+func (a *fakeRedisAdapter) GroupString() gredis.IGroupString       { return nil }
+
+func TestGfEntry_RegisterRedisAdapterFunc(t *testing.T) {
+	defer gredis.RegisterAdapterFunc(nil)
+
+	bootStr := `
+gf:
+  - name: ut-redis
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-redis"].(*GfEntry)
+	entry.RegisterRedisAdapterFunc(func(config *gredis.Config) gredis.Adapter {
+		return &fakeRedisAdapter{}
+	})
+
+	redis, err := gredis.New(&gredis.Config{Address: "localhost:6379"})
+	assert.Nil(t, err)
+	_, err = redis.Do(context.TODO(), "set", "key", "value")
+	assert.Nil(t, err)
+
+	families, err := entry.GetPromRegistry().Gather()
+	assert.Nil(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "rk_gredis_elapsedSecond" {
+			continue
+		}
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			if label.GetName() == "entryName" {
+				found = label.GetValue() == "ut-redis"
+			}
+		}
+	}
+	assert.True(t, found)
+}
+
+type fakeGdbDB struct {
+	gdb.DB
+}
+
+func (db *fakeGdbDB) DoCommit(ctx context.Context, in gdb.DoCommitInput) (gdb.DoCommitOutput, error) {
+	return gdb.DoCommitOutput{}, nil
+}
+
+type fakeGdbDriver struct{}
+
+func (d *fakeGdbDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &fakeGdbDB{}, nil
+}
+
+func TestGfEntry_RegisterGdbDriver(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-gdb
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-gdb"].(*GfEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+	assert.Nil(t, entry.RegisterGdbDriver("ut-gdb", &fakeGdbDriver{}))
+
+	db, err := gdb.New(gdb.ConfigNode{Type: "ut-gdb"})
+	assert.Nil(t, err)
+	_, err = db.DoCommit(context.TODO(), gdb.DoCommitInput{
+		Sql:  "SELECT * FROM user",
+		Type: gdb.SqlTypeQueryContext,
+	})
+	assert.Nil(t, err)
+
+	families, err := entry.GetPromRegistry().Gather()
+	assert.Nil(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "rk_gdb_elapsedSecond" {
+			continue
+		}
+		for _, label := range family.GetMetric()[0].GetLabel() {
+			if label.GetName() == "entryName" {
+				found = label.GetValue() == "ut-gdb"
+			}
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRegisterGfEntryYAML_PromCollectors(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-prom-collectors
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+      collectors:
+        go: true
+        process: true
+        buildInfo: true
+  - name: ut-prom-disabled
+    port: 0
+    enabled: true
+`
+	entries := RegisterGfEntryYAML([]byte(bootStr))
+	assert.Nil(t, entries["ut-prom-disabled"].(*GfEntry).GetPromRegistry())
+
+	registry := entries["ut-prom-collectors"].(*GfEntry).GetPromRegistry()
+	assert.NotNil(t, registry)
+
+	// custom metrics registered by business code
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "ut_counter"})
+	assert.Nil(t, registry.Register(counter))
+	counter.Inc()
+
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+
+	names := map[string]bool{}
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["go_sched_goroutines_goroutines"])
+	assert.True(t, names["process_cpu_seconds_total"])
+	assert.True(t, names["go_build_info"])
+	assert.True(t, names["ut_counter"])
+}
+
+func TestRegisterGfEntryYAML_PromRawPaths(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-prom-raw-paths
+    port: 0
+    enabled: true
+    middleware:
+      prom:
+        enabled: true
+        ignore: ["/ut-ignore"]
+        rawPaths: ["/ut-raw"]
+        size:
+          enabled: true
+        inFlight:
+          enabled: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-prom-raw-paths"].(*GfEntry)
+	for _, info := range entry.middlewareInfos {
+		if info.Name != "prom" {
+			continue
+		}
+		config := info.Config.(rkgfprom.BootConfig)
+		assert.True(t, config.Enabled)
+		assert.Equal(t, []string{"/ut-ignore"}, config.Ignore)
+		assert.Equal(t, []string{"/ut-raw"}, config.RawPaths)
+		assert.True(t, config.Size.Enabled)
+		assert.True(t, config.InFlight.Enabled)
+	}
+}
+
+func TestRegisterGfEntryYAML_PromExemplar(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-prom-exemplar
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+    middleware:
+      prom:
+        enabled: true
+        histogram:
+          enabled: true
+      trace:
+        enabled: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-prom-exemplar"].(*GfEntry)
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	addr := "http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10)
+	resp, err := http.Get(addr + "/ut")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, addr+entry.PromEntry.Path, nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/openmetrics-text")
+	assert.Contains(t, string(body), `rk_prom_elapsedSecond_bucket`)
+	assert.Contains(t, string(body), `# {trace_id="`)
+}
+
+// otlpReceiver is an in-process OTLP collector which receives exported metrics.
+type otlpReceiver struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	requests chan *collectormetrics.ExportMetricsServiceRequest
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	r.requests <- req
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func TestRegisterGfEntryYAML_OtelMetrics(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	receiver := &otlpReceiver{requests: make(chan *collectormetrics.ExportMetricsServiceRequest, 10)}
+	server := grpc.NewServer()
+	collectormetrics.RegisterMetricsServiceServer(server, receiver)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	bootStr := `
+gf:
+  - name: ut-otel-metrics
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+    middleware:
+      ignore: ["/ut-global-ignore"]
+      prom:
+        enabled: true
+      otelMetrics:
+        enabled: true
+        ignore: ["/ut-ignore"]
+        exporter:
+          otlp:
+            enabled: true
+            endpoint: ` + lis.Addr().String() + `
+            insecure: true
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-otel-metrics"].(*GfEntry)
+	assert.NotNil(t, entry.meterProvider)
+	names := make([]string, 0)
+	for _, info := range entry.middlewareInfos {
+		names = append(names, info.Name)
+		if info.Name != "otelMetrics" {
+			continue
+		}
+		config := info.Config.(rkgfotelmetrics.BootConfig)
+		assert.True(t, config.Enabled)
+		assert.Equal(t, []string{"/ut-ignore", "/ut-global-ignore"}, config.Ignore)
+		assert.True(t, config.Exporter.Otlp.Insecure)
+	}
+	assert.Equal(t, []string{"logging", "panic", "prom", "otelMetrics", "trace", "response"}, names[:6])
+
+	// side by side with prom middleware
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+
+	addr := "http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10)
+	resp, err := http.Get(addr + "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = http.Get(addr + entry.PromEntry.Path)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `rk_prom_resCode`)
+
+	// metrics are flushed to OTLP receiver while interrupting
+	entry.Interrupt(context.TODO())
+
+	select {
+	case req := <-receiver.requests:
+		metrics := make([]string, 0)
+		for _, rm := range req.GetResourceMetrics() {
+			for _, sm := range rm.GetScopeMetrics() {
+				for _, m := range sm.GetMetrics() {
+					metrics = append(metrics, m.GetName())
+				}
+			}
+		}
+		assert.Contains(t, metrics, rkgfotelmetrics.MetricsNameDuration)
+	case <-time.After(5 * time.Second):
+		t.Fatal("metrics not received by OTLP receiver")
+	}
+}
+
+func TestRegisterGfEntryYAML_Response(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-response
+    port: 0
+    enabled: true
+    prom:
+      enabled: true
+    middleware:
+      meta:
+        enabled: true
+      prom:
+        enabled: true
+      response:
+        enabled: true
+        keys:
+          data: result
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-response"].(*GfEntry)
+	entry.Server.BindHandler("/ut-panic", func(ctx *ghttp.Request) {
+		panic("ut-panic")
+	})
+	entry.Server.BindHandler("/ut-error", func(ctx *ghttp.Request) {
+		ctx.SetError(errors.New("ut-error"))
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/ut-panic")
+	assert.Nil(t, err)
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&m))
+	assert.Equal(t, float64(http.StatusInternalServerError), m["code"])
+	assert.Equal(t, "Panic occurs", m["message"])
+	assert.NotEmpty(t, m["result"])
+	assert.Equal(t, resp.Header.Get("X-Request-Id"), m["requestId"])
+	assert.NotEmpty(t, m["requestId"])
+
+	// status written by envelope is recorded by prom middleware
+	resp, err = http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/ut-error")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	resp.Body.Close()
+
+	families, err := entry.PromEntry.Gatherer.Gather()
+	assert.Nil(t, err)
+	resCodes := make([]string, 0)
+	for _, family := range families {
+		if family.GetName() != "rk_prom_resCode" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["restPath"] == "/ut-error" {
+				resCodes = append(resCodes, labels["resCode"])
+			}
+		}
+	}
+	assert.Equal(t, []string{"500"}, resCodes)
+}
+
 func getClient() *gclient.Client {
 	time.Sleep(100 * time.Millisecond)
 	client := g.Client()
@@ -269,6 +1298,63 @@ func generateCerts() ([]byte, []byte) {
 	return pem.EncodeToMemory(c), pem.EncodeToMemory(k)
 }
 
+func generateCA(t *testing.T, dir string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ut-ca"},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(2 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(path.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	return cert, key
+}
+
+func generateSignedCert(t *testing.T, dir, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: name},
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(2 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{name + ".rk"},
+	}
+	if usage == x509.ExtKeyUsageServerAuth {
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	assert.Nil(t, os.WriteFile(path.Join(dir, name+".pem"), certPem, 0600))
+	assert.Nil(t, os.WriteFile(path.Join(dir, name+"-key.pem"), keyPem, 0600))
+
+	res, err := tls.X509KeyPair(certPem, keyPem)
+	assert.Nil(t, err)
+
+	return res
+}
+
 func validateServerIsUp(t *testing.T, port uint64, isTls bool) {
 	// sleep for 2 seconds waiting server startup
 	time.Sleep(2 * time.Second)
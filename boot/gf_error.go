@@ -0,0 +1,78 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+const (
+	// ServerErrorOpListen failed to listen on port, unix socket or systemd socket
+	ServerErrorOpListen = "listen"
+	// ServerErrorOpTls failed to build TLS config
+	ServerErrorOpTls = "tls"
+	// ServerErrorOpStart failed to start ghttp.Server
+	ServerErrorOpStart = "start"
+	// ServerErrorOpServe failed while serving after started
+	ServerErrorOpServe = "serve"
+)
+
+// ServerError is returned by GfEntry.BootstrapWithError() and sent to GfEntry.FatalErrors()
+// while server failed to start or serve.
+type ServerError struct {
+	EntryName string
+	Op        string
+	Err       error
+}
+
+// Error returns message of error.
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("gf entry %s failed to %s, %v", e.EntryName, e.Op, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// newServerError wraps err with entry name and operation.
+func (entry *GfEntry) newServerError(op string, err error) *ServerError {
+	return &ServerError{
+		EntryName: entry.entryName,
+		Op:        op,
+		Err:       err,
+	}
+}
+
+// reportFatal sends error to FatalErrors() channel without blocking.
+func (entry *GfEntry) reportFatal(err error) {
+	select {
+	case entry.fatalErrors <- err:
+	default:
+	}
+}
+
+// onAcceptErr reports fatal accept error of server listener to FatalErrors() channel
+// if entry was bootstrapped with BootstrapWithError().
+//
+// ghttp.Server exits process with logger if serving failed with errors other than http.ErrServerClosed,
+// so http.ErrServerClosed would be returned instead once error was reported.
+func (entry *GfEntry) onAcceptErr(err error) error {
+	if !entry.reportErrors || errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	// temporary error would be retried by http.Server
+	if ne, ok := err.(net.Error); ok && ne.Temporary() {
+		return err
+	}
+
+	entry.reportFatal(entry.newServerError(ServerErrorOpServe, err))
+	return http.ErrServerClosed
+}
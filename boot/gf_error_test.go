@@ -0,0 +1,75 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"context"
+	"errors"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+type failingListener struct {
+	net.Listener
+	fail chan struct{}
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	<-l.fail
+	return nil, errors.New("ut-accept-failure")
+}
+
+func TestGfEntry_BootstrapWithError_Listen(t *testing.T) {
+	occupied, err := net.Listen("tcp", ":0")
+	assert.Nil(t, err)
+	defer occupied.Close()
+
+	entry := RegisterGfEntry(
+		WithName("ut-start-failure"),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithPort(uint64(occupied.Addr().(*net.TCPAddr).Port)))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	err = entry.BootstrapWithError(context.TODO())
+	assert.NotNil(t, err)
+
+	var serverErr *ServerError
+	assert.True(t, errors.As(err, &serverErr))
+	assert.Equal(t, "ut-start-failure", serverErr.EntryName)
+	assert.Equal(t, ServerErrorOpListen, serverErr.Op)
+	assert.NotNil(t, errors.Unwrap(err))
+}
+
+func TestGfEntry_BootstrapWithError_Serve(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer raw.Close()
+	ln := &failingListener{Listener: raw, fail: make(chan struct{})}
+
+	entry := RegisterGfEntry(
+		WithName("ut-serve-failure"),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithListener(ln))
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// process should keep running and error should be reported
+	close(ln.fail)
+	select {
+	case err := <-entry.FatalErrors():
+		var serverErr *ServerError
+		assert.True(t, errors.As(err, &serverErr))
+		assert.Equal(t, ServerErrorOpServe, serverErr.Op)
+		assert.EqualError(t, serverErr.Err, "ut-accept-failure")
+	case <-time.After(2 * time.Second):
+		assert.Fail(t, "fatal error was not reported")
+	}
+}
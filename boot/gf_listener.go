@@ -0,0 +1,234 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// systemdListenFdsStart is the first file descriptor passed by systemd socket activation, SD_LISTEN_FDS_START
+	systemdListenFdsStart = 3
+)
+
+// BootListener is bootstrap config of listener which replaces TCP port.
+type BootListener struct {
+	Unix struct {
+		Path     string `yaml:"path" json:"path"`
+		FileMode string `yaml:"fileMode" json:"fileMode"`
+	} `yaml:"unix" json:"unix"`
+	Systemd bool `yaml:"systemd" json:"systemd"`
+}
+
+// listen create listener of entry.
+//
+// Priority: listener provided by user, systemd socket activation, unix domain socket and TCP port.
+// Random port would be picked by OS if port is 0.
+func (entry *GfEntry) listen() (net.Listener, error) {
+	switch {
+	case entry.preListener != nil:
+		return entry.preListener, nil
+	case entry.systemdSocket:
+		return listenSystemd(entry.entryName)
+	case len(entry.unixSocketPath) > 0:
+		return listenUnix(entry.unixSocketPath, entry.unixSocketMode)
+	}
+
+	return net.Listen("tcp", ":"+strconv.FormatUint(entry.Port, 10))
+}
+
+// listenUnix listen on unix domain socket and change file mode of socket.
+//
+// Socket is created in a private directory and renamed to path after file mode changed,
+// so that it is never reachable with file mode derived from umask.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	// remove stale socket file left by previous process
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".rk-gf-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "sock")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// socket file is removed by entry while interrupting
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if mode != 0 {
+		if err := os.Chmod(tmp, mode); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	return &unixListener{
+		Listener: ln,
+		addr:     &net.UnixAddr{Name: path, Net: "unix"},
+	}, nil
+}
+
+// unixListener wraps listener of unix domain socket which was renamed to path of addr.
+type unixListener struct {
+	net.Listener
+	addr net.Addr
+}
+
+// Addr returns address with path of socket.
+func (l *unixListener) Addr() net.Addr {
+	return l.addr
+}
+
+// listenSystemd create listener from file descriptor passed by systemd socket activation.
+func listenSystemd(name string) (net.Listener, error) {
+	fd, err := systemdListenFd(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f := os.NewFile(fd, "LISTEN_FD_"+strconv.Itoa(int(fd)))
+	defer f.Close()
+
+	return net.FileListener(f)
+}
+
+// systemdListenFd returns file descriptor passed by systemd with LISTEN_PID and LISTEN_FDS.
+//
+// If LISTEN_FDNAMES exists, descriptor whose name matches entry name would be returned,
+// otherwise, the first descriptor would be returned.
+func systemdListenFd(name string) (uintptr, error) {
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0, errors.New("LISTEN_PID does not match current process, not activated by systemd")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return 0, errors.New("LISTEN_FDS is missing, no socket passed by systemd")
+	}
+
+	if names := os.Getenv("LISTEN_FDNAMES"); len(names) > 0 {
+		for i, fdName := range strings.Split(names, ":") {
+			if fdName == name && i < count {
+				return uintptr(systemdListenFdsStart + i), nil
+			}
+		}
+
+		return 0, fmt.Errorf("socket named %s is missing in LISTEN_FDNAMES", name)
+	}
+
+	return systemdListenFdsStart, nil
+}
+
+// drainListener wraps net.Listener and closes newly accepted connections once draining started.
+//
+// TCP and unix domain socket connections are wrapped with headerTimeoutConn if headerTimeout is provided.
+type drainListener struct {
+	net.Listener
+	draining      int32
+	onAcceptErr   func(error) error
+	headerTimeout time.Duration
+	conns         sync.Map
+	unixSeq       uint64
+}
+
+// Accept waits for and returns the next connection which is not refused.
+func (l *drainListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			if l.onAcceptErr != nil {
+				err = l.onAcceptErr(err)
+			}
+			return nil, err
+		}
+
+		if atomic.LoadInt32(&l.draining) == 1 {
+			conn.Close()
+			continue
+		}
+
+		if l.headerTimeout < 1 {
+			return conn, nil
+		}
+
+		// remote address identifies connection in handler, peer of unix domain socket is unnamed,
+		// so unique address is assigned to it
+		switch conn.RemoteAddr().(type) {
+		case *net.TCPAddr:
+		case *net.UnixAddr:
+			conn = &unixConn{
+				Conn:       conn,
+				remoteAddr: &net.UnixAddr{Name: "@" + strconv.FormatUint(atomic.AddUint64(&l.unixSeq, 1), 10), Net: "unix"},
+			}
+		default:
+			return conn, nil
+		}
+
+		key := conn.RemoteAddr().String()
+		wrapped := newHeaderTimeoutConn(conn, l.headerTimeout, func() {
+			l.conns.Delete(key)
+		})
+		l.conns.Store(key, wrapped)
+		return wrapped, nil
+	}
+}
+
+// unixConn wraps connection of unix domain socket with unique remote address.
+type unixConn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+// RemoteAddr returns unique remote address assigned by listener.
+func (c *unixConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// startRequest marks request header of connection with remote address as read,
+// returns function which should be called once request is served.
+func (l *drainListener) startRequest(remoteAddr string) func() {
+	if v, ok := l.conns.Load(remoteAddr); ok {
+		return v.(*headerTimeoutConn).startRequest()
+	}
+
+	return func() {}
+}
+
+// Addr returns address of listener.
+//
+// ghttp.Server only accepts listeners with TCP address, so address of unix domain socket
+// would be reported as 127.0.0.1:0.
+func (l *drainListener) Addr() net.Addr {
+	if addr, ok := l.Listener.Addr().(*net.TCPAddr); ok {
+		return addr
+	}
+
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+func (l *drainListener) drain() {
+	atomic.StoreInt32(&l.draining, 1)
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGfEntry_UnixSocket(t *testing.T) {
+	sock := path.Join(t.TempDir(), "ut.sock")
+
+	entry := RegisterGfEntry(
+		WithName("ut-unix"),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithUnixSocket(sock, 0600))
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.WriteStatus(http.StatusOK)
+	})
+	entry.Bootstrap(context.TODO())
+	time.Sleep(time.Second)
+
+	info, err := os.Stat(sock)
+	assert.Nil(t, err)
+	if info != nil {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	// socket is created in private directory which is removed after renaming
+	files, err := os.ReadDir(path.Dir(sock))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	// socket path is reported instead of port
+	assert.Zero(t, entry.Port)
+	assert.Equal(t, sock, entry.GetAddr().String())
+	assert.Equal(t, "unix:"+sock+":", entry.listenHost())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://unix/ut")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	entry.Interrupt(context.TODO())
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGfEntry_PreOpenedListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	entry := RegisterGfEntry(
+		WithName("ut-listener"),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithListener(ln))
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+	validateServerIsUp(t, uint64(port), false)
+}
+
+func TestSystemdListenFd(t *testing.T) {
+	// not activated by systemd
+	t.Setenv("LISTEN_PID", "")
+	_, err := systemdListenFd("ut")
+	assert.NotNil(t, err)
+
+	// without names
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	fd, err := systemdListenFd("ut")
+	assert.Nil(t, err)
+	assert.Equal(t, uintptr(3), fd)
+
+	// with names
+	t.Setenv("LISTEN_FDNAMES", "other:ut")
+	fd, err = systemdListenFd("ut")
+	assert.Nil(t, err)
+	assert.Equal(t, uintptr(4), fd)
+
+	_, err = systemdListenFd("missing")
+	assert.NotNil(t, err)
+}
+
+func TestGfEntry_RandomPort(t *testing.T) {
+	entry := RegisterGfEntry(
+		WithName("ut-random-port"),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithPort(0))
+	assert.Nil(t, entry.GetAddr())
+
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.WriteStatus(http.StatusOK)
+	})
+	entry.Bootstrap(context.TODO())
+	defer entry.Interrupt(context.TODO())
+
+	// address is available once Bootstrap returned
+	addr, ok := entry.GetAddr().(*net.TCPAddr)
+	assert.True(t, ok)
+	if !ok {
+		return
+	}
+	assert.NotZero(t, addr.Port)
+	assert.Equal(t, uint64(addr.Port), entry.Port)
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.Itoa(addr.Port) + "/ut")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/panic"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/panic"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// BootManagement is bootstrap config of management server which serves common service, prom and pprof endpoints.
+type BootManagement struct {
+	Port uint64 `yaml:"port" json:"port"`
+}
+
+// newManagementServer creates ghttp.Server for internal endpoints with panic middleware only.
+func (entry *GfEntry) newManagementServer() *ghttp.Server {
+	server := g.Server(entry.entryName + "-management")
+	server.SetDumpRouterMap(false)
+	entry.serverConfig.apply(server)
+	server.SetLogger(rkgfinter.NewGLogger(entry.LoggerEntry))
+	server.Use(rkgfpanic.Middleware(
+		rkmidpanic.WithEntryNameAndType(entry.entryName, GfEntryType)))
+
+	return server
+}
+
+// internalServer returns server which common service, prom and pprof endpoints are bound to.
+func (entry *GfEntry) internalServer() *ghttp.Server {
+	if entry.managementServer != nil {
+		return entry.managementServer
+	}
+
+	return entry.Server
+}
+
+// internalPort returns port which common service, prom and pprof endpoints are served on.
+func (entry *GfEntry) internalPort() uint64 {
+	if entry.managementServer != nil {
+		return entry.managementPort
+	}
+
+	return entry.Port
+}
+
+// listenHost returns host of url which server of entry listens on, like localhost:8080.
+//
+// Unix domain socket is formatted like unix:/var/run/greeter.sock: as nginx does.
+func (entry *GfEntry) listenHost() string {
+	if addr, ok := entry.GetAddr().(*net.UnixAddr); ok {
+		return "unix:" + addr.Name + ":"
+	}
+
+	return "localhost:" + strconv.FormatUint(entry.Port, 10)
+}
+
+// internalHost returns host of url which common service, prom and pprof endpoints are served on.
+func (entry *GfEntry) internalHost() string {
+	if entry.managementServer != nil {
+		return "localhost:" + strconv.FormatUint(entry.managementPort, 10)
+	}
+
+	return entry.listenHost()
+}
+
+// startManagementServer start management server if management port provided.
+func (entry *GfEntry) startManagementServer(event rkquery.Event, logger *zap.Logger) error {
+	if entry.managementServer == nil {
+		return nil
+	}
+
+	if !entry.IsCommonServiceEnabled() && !entry.IsPromEnabled() && !entry.IsPProfEnabled() {
+		logger.Warn("None of common service, prom and pprof is enabled, skip starting management server.")
+		return nil
+	}
+
+	raw, err := net.Listen("tcp", ":"+strconv.FormatUint(entry.managementPort, 10))
+	if err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while listening on management port.", event.ListPayloads()...)
+		return entry.newServerError(ServerErrorOpListen, err)
+	}
+
+	// read header timeout is enforced by listener, the same as server of entry
+	ln := &drainListener{
+		Listener:      raw,
+		headerTimeout: entry.serverConfig.readHeaderTimeout,
+	}
+	entry.managementServer.SetHandler(func(writer http.ResponseWriter, req *http.Request) {
+		defer ln.startRequest(req.RemoteAddr)()
+		entry.managementServer.ServeHTTP(writer, req)
+	})
+
+	if err := entry.managementServer.SetListener(ln); err != nil {
+		ln.Close()
+		event.AddErr(err)
+		logger.Error("Error occurs while setting management listener.", event.ListPayloads()...)
+		return entry.newServerError(ServerErrorOpListen, err)
+	}
+
+	if err := entry.managementServer.Start(); err != nil {
+		ln.Close()
+		event.AddErr(err)
+		logger.Error("Error occurs while starting management server.", event.ListPayloads()...)
+		return entry.newServerError(ServerErrorOpStart, err)
+	}
+
+	return nil
+}
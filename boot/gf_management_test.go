@@ -0,0 +1,71 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestGfEntry_ManagementPort(t *testing.T) {
+	commonServiceEntry := rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+		Enabled: true,
+	})
+	promEntry := rkentry.RegisterPromEntry(&rkentry.BootProm{
+		Enabled: true,
+	})
+
+	entry := RegisterGfEntry(
+		WithName("ut-management"),
+		WithPort(0),
+		WithManagementPort(8090),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCommonServiceEntry(commonServiceEntry),
+		WithPromEntry(promEntry))
+	entry.AddMiddleware(func(ctx *ghttp.Request) {
+		ctx.Response.Header().Set("X-Ut-User", "true")
+		ctx.Middleware.Next()
+	})
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.WriteStatus(http.StatusOK)
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// internal endpoints are served on management port without user middlewares
+	for _, p := range []string{commonServiceEntry.ReadyPath, commonServiceEntry.AlivePath, promEntry.Path} {
+		resp, err := http.Get("http://127.0.0.1:8090" + p)
+		assert.Nil(t, err)
+		if resp != nil {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, p)
+			assert.Empty(t, resp.Header.Get("X-Ut-User"))
+		}
+	}
+
+	// internal endpoints are not reachable from main port
+	mainAddr := "http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10)
+	resp, err := http.Get(mainAddr + commonServiceEntry.ReadyPath)
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp, err = http.Get(mainAddr + "/ut")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get("X-Ut-User"))
+	}
+}
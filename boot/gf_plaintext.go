@@ -0,0 +1,106 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// PlaintextModeRedirect redirects every request on plaintext port to TLS port
+	PlaintextModeRedirect = "redirect"
+	// PlaintextModeInternal serves common service and prom endpoints only on plaintext port
+	PlaintextModeInternal = "internal"
+)
+
+// BootPlaintext is bootstrap config of plaintext port served next to TLS port.
+type BootPlaintext struct {
+	Port uint64 `yaml:"port" json:"port"`
+	Mode string `yaml:"mode" json:"mode"`
+}
+
+// startPlaintextServer start plaintext server next to TLS server if plaintext port provided.
+func (entry *GfEntry) startPlaintextServer(event rkquery.Event, logger *zap.Logger) error {
+	if entry.plaintextPort == 0 {
+		return nil
+	}
+
+	var handler http.Handler
+	switch entry.plaintextMode {
+	case PlaintextModeInternal:
+		mux := http.NewServeMux()
+		for p, h := range entry.internalHandlers() {
+			mux.HandleFunc(p, h)
+		}
+		handler = mux
+	default:
+		if !entry.IsTlsEnabled() {
+			logger.Warn("TLS is not enabled, skip redirecting plaintext port.")
+			return nil
+		}
+		handler = http.HandlerFunc(entry.redirectToTls)
+	}
+
+	raw, err := net.Listen("tcp", ":"+strconv.FormatUint(entry.plaintextPort, 10))
+	if err != nil {
+		event.AddErr(err)
+		logger.Error("Error occurs while listening on plaintext port.", event.ListPayloads()...)
+		return entry.newServerError(ServerErrorOpListen, err)
+	}
+
+	entry.plaintextServer = &http.Server{Handler: handler}
+	entry.serverConfig.applyHttp(entry.plaintextServer)
+	go func(server *http.Server, ln net.Listener) {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			event.AddErr(err)
+			logger.Error("Error occurs while serving plaintext port.", event.ListPayloads()...)
+			if !entry.reportErrors {
+				rkentry.ShutdownWithError(err)
+			}
+			entry.reportFatal(entry.newServerError(ServerErrorOpServe, err))
+		}
+	}(entry.plaintextServer, &drainListener{Listener: raw})
+
+	return nil
+}
+
+// redirectToTls redirects request to the same path and query on TLS port with 308.
+func (entry *GfEntry) redirectToTls(writer http.ResponseWriter, req *http.Request) {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(req.Host); err == nil {
+		host = h
+	}
+
+	if entry.Port != 443 {
+		host = net.JoinHostPort(host, strconv.FormatUint(entry.Port, 10))
+	}
+
+	http.Redirect(writer, req, "https://"+host+req.URL.RequestURI(), http.StatusPermanentRedirect)
+}
+
+// internalHandlers returns handlers of common service and prom endpoints keyed by path.
+func (entry *GfEntry) internalHandlers() map[string]http.HandlerFunc {
+	res := make(map[string]http.HandlerFunc)
+
+	if entry.IsCommonServiceEnabled() {
+		res[entry.CommonServiceEntry.ReadyPath] = entry.ready
+		res[entry.CommonServiceEntry.GcPath] = entry.CommonServiceEntry.Gc
+		res[entry.CommonServiceEntry.InfoPath] = entry.CommonServiceEntry.Info
+		res[entry.CommonServiceEntry.AlivePath] = entry.CommonServiceEntry.Alive
+		res[entry.routesPath()] = entry.routes
+	}
+
+	if entry.IsPromEnabled() {
+		res[entry.PromEntry.Path] = newPromHandler(entry.PromEntry).ServeHTTP
+	}
+
+	return res
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"net/http"
+)
+
+// BootProm is bootstrap config of rkentry.PromEntry with collectors registered into prometheus registry of entry.
+type BootProm struct {
+	rkentry.BootProm `yaml:",inline" json:",inline" mapstructure:",squash"`
+	Collectors       BootPromCollectors `yaml:"collectors" json:"collectors"`
+}
+
+// BootPromCollectors is bootstrap config of collectors registered into prometheus registry of entry.
+//
+// Go collector with default series is always registered by rkentry.PromEntry, enable Go to expose
+// all series of runtime/metrics instead.
+type BootPromCollectors struct {
+	Go        bool `yaml:"go" json:"go"`
+	Process   bool `yaml:"process" json:"process"`
+	BuildInfo bool `yaml:"buildInfo" json:"buildInfo"`
+}
+
+// registerPromCollectors registers collectors enabled in config into registry.
+func registerPromCollectors(registry *prometheus.Registry, config *BootPromCollectors) {
+	if registry == nil {
+		return
+	}
+
+	if config.Go {
+		// replace Go collector registered by rkentry.PromEntry
+		registry.Unregister(collectors.NewGoCollector())
+		registry.MustRegister(collectors.NewGoCollector(
+			collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)))
+	}
+
+	if config.Process {
+		registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
+	if config.BuildInfo {
+		registry.MustRegister(collectors.NewBuildInfoCollector())
+	}
+}
+
+// newPromHandler returns handler of PromEntry.
+//
+// OpenMetrics format would be served if requested by scraper, which is required to expose exemplars.
+func newPromHandler(entry *rkentry.PromEntry) http.Handler {
+	return promhttp.HandlerFor(entry.Gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
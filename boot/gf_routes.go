@@ -0,0 +1,196 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"encoding/json"
+	"github.com/gogf/gf/v2/debug/gdebug"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/jwt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const redacted = "******"
+
+// middlewareInfo is configuration of middleware registered by entry, sensitive values are redacted.
+type middlewareInfo struct {
+	Name      string      `json:"name"`
+	Enabled   bool        `json:"enabled"`
+	Ignore    []string    `json:"ignore"`
+	Config    interface{} `json:"config"`
+	ignorable bool
+}
+
+// routeInfo is route served by entry.
+type routeInfo struct {
+	Server      string   `json:"server"`
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+	IgnoredBy   []string `json:"ignoredBy"`
+}
+
+// routesResp is response of routes endpoint.
+type routesResp struct {
+	EntryName   string           `json:"entryName"`
+	EntryType   string           `json:"entryType"`
+	Routes      []routeInfo      `json:"routes"`
+	Middlewares []middlewareInfo `json:"middlewares"`
+}
+
+// newMiddlewareInfo creates middlewareInfo of middleware which skips paths with ignore prefixes and global ignore prefixes.
+func newMiddlewareInfo(name string, enabled bool, ignore []string, config interface{}) middlewareInfo {
+	if ignore == nil {
+		ignore = []string{}
+	}
+
+	return middlewareInfo{
+		Name:      name,
+		Enabled:   enabled,
+		Ignore:    ignore,
+		Config:    config,
+		ignorable: true,
+	}
+}
+
+// redactAuth hides credentials in auth middleware config.
+func redactAuth(config rkmidauth.BootConfig) rkmidauth.BootConfig {
+	config.Basic = redactSlice(config.Basic)
+	config.ApiKey = redactSlice(config.ApiKey)
+	return config
+}
+
+// redactJwt hides keys in jwt middleware config.
+func redactJwt(config rkmidjwt.BootConfig) rkmidjwt.BootConfig {
+	if config.Symmetric != nil {
+		symmetric := *config.Symmetric
+		symmetric.Token = redactString(symmetric.Token)
+		config.Symmetric = &symmetric
+	}
+
+	if config.Asymmetric != nil {
+		asymmetric := *config.Asymmetric
+		asymmetric.PrivateKey = redactString(asymmetric.PrivateKey)
+		config.Asymmetric = &asymmetric
+	}
+
+	return config
+}
+
+func redactString(s string) string {
+	if len(s) > 0 {
+		return redacted
+	}
+
+	return s
+}
+
+func redactSlice(s []string) []string {
+	res := make([]string, 0, len(s))
+	for range s {
+		res = append(res, redacted)
+	}
+
+	return res
+}
+
+// routesPath returns path of routes endpoint which shares prefix with common service.
+func (entry *GfEntry) routesPath() string {
+	return path.Join(path.Dir(entry.CommonServiceEntry.ReadyPath), "routes")
+}
+
+// listRoutes lists handlers served by main server and management server.
+func (entry *GfEntry) listRoutes() []routeInfo {
+	res := make([]routeInfo, 0)
+
+	servers := []struct {
+		name   string
+		server *ghttp.Server
+	}{
+		{"main", entry.Server},
+		{"management", entry.managementServer},
+	}
+
+	for _, s := range servers {
+		if s.server == nil {
+			continue
+		}
+
+		for _, item := range s.server.GetRoutes() {
+			if !item.IsServiceHandler {
+				continue
+			}
+
+			route := routeInfo{
+				Server:      s.name,
+				Method:      item.Method,
+				Path:        item.Route,
+				Handler:     item.Handler.Name,
+				Middlewares: make([]string, 0),
+				IgnoredBy:   make([]string, 0),
+			}
+
+			if s.server == entry.Server {
+				for _, mid := range entry.middlewareInfos {
+					if !mid.Enabled {
+						continue
+					}
+					route.Middlewares = append(route.Middlewares, mid.Name)
+					if entry.ignoredBy(mid, item.Route) {
+						route.IgnoredBy = append(route.IgnoredBy, mid.Name)
+					}
+				}
+			}
+
+			for _, f := range item.Handler.Middleware {
+				route.Middlewares = append(route.Middlewares, gdebug.FuncName(f))
+			}
+
+			res = append(res, route)
+		}
+	}
+
+	return res
+}
+
+// ignoredBy checks whether path is ignored by middleware, same as ShouldIgnore() of middleware option set.
+func (entry *GfEntry) ignoredBy(mid middlewareInfo, urlPath string) bool {
+	if !mid.ignorable {
+		return false
+	}
+
+	for i := range mid.Ignore {
+		if strings.HasPrefix(urlPath, mid.Ignore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(urlPath)
+}
+
+// routes handles routes endpoint which lists routes and middleware configuration of entry.
+func (entry *GfEntry) routes(writer http.ResponseWriter, req *http.Request) {
+	middlewares := entry.middlewareInfos
+	if middlewares == nil {
+		middlewares = []middlewareInfo{}
+	}
+
+	bytes, _ := json.Marshal(&routesResp{
+		EntryName:   entry.entryName,
+		EntryType:   entry.entryType,
+		Routes:      entry.listRoutes(),
+		Middlewares: middlewares,
+	})
+
+	writer.Header().Set(rkmid.HeaderContentType, "application/json; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(bytes)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestGfEntry_Routes(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-routes
+    port: 0
+    enabled: true
+    commonService:
+      enabled: true
+    middleware:
+      logging:
+        enabled: true
+        ignore: ["/ut-ignored"]
+      auth:
+        enabled: true
+        basic: ["user:pass"]
+        ignore: ["/"]
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-routes"].(*GfEntry)
+	entry.Server.BindHandler("/ut-ignored", func(ctx *ghttp.Request) {
+		ctx.Response.WriteStatus(http.StatusOK)
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/rk/v1/routes")
+	assert.Nil(t, err)
+	if resp == nil {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	raw, _ := io.ReadAll(resp.Body)
+	res := &routesResp{}
+	assert.Nil(t, json.Unmarshal(raw, res))
+	assert.Equal(t, "ut-routes", res.EntryName)
+
+	var found bool
+	for _, route := range res.Routes {
+		if route.Path == "/ut-ignored" {
+			found = true
+			assert.Equal(t, "main", route.Server)
+			assert.Equal(t, []string{"logging", "panic", "auth"}, route.Middlewares)
+			assert.Equal(t, []string{"logging", "auth"}, route.IgnoredBy)
+		}
+	}
+	assert.True(t, found)
+
+	// credentials should be redacted
+	assert.NotContains(t, string(raw), "user:pass")
+	assert.Len(t, res.Middlewares, 15)
+}
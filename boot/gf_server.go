@@ -0,0 +1,216 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"fmt"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BootServer is bootstrap config of timeouts and limits of ghttp.Server.
+//
+// Zero value means default value of ghttp.ServerConfig would be used.
+type BootServer struct {
+	ReadTimeoutMs       int   `yaml:"readTimeoutMs" json:"readTimeoutMs"`
+	ReadHeaderTimeoutMs int   `yaml:"readHeaderTimeoutMs" json:"readHeaderTimeoutMs"`
+	WriteTimeoutMs      int   `yaml:"writeTimeoutMs" json:"writeTimeoutMs"`
+	IdleTimeoutMs       int   `yaml:"idleTimeoutMs" json:"idleTimeoutMs"`
+	MaxHeaderBytes      int   `yaml:"maxHeaderBytes" json:"maxHeaderBytes"`
+	MaxBodyBytes        int64 `yaml:"maxBodyBytes" json:"maxBodyBytes"`
+	KeepAlive           *bool `yaml:"keepAlive" json:"keepAlive"`
+}
+
+// validate checks values of BootServer.
+func (b *BootServer) validate() error {
+	for k, v := range map[string]int64{
+		"readTimeoutMs":       int64(b.ReadTimeoutMs),
+		"readHeaderTimeoutMs": int64(b.ReadHeaderTimeoutMs),
+		"writeTimeoutMs":      int64(b.WriteTimeoutMs),
+		"idleTimeoutMs":       int64(b.IdleTimeoutMs),
+		"maxHeaderBytes":      int64(b.MaxHeaderBytes),
+		"maxBodyBytes":        b.MaxBodyBytes,
+	} {
+		if v < 0 {
+			return fmt.Errorf("invalid server.%s:%d, negative value is not allowed", k, v)
+		}
+	}
+
+	return nil
+}
+
+// serverConfig is timeouts and limits of ghttp.Server set by user.
+//
+// Zero value means it is not set, value of ghttp.Server like one loaded from config file of GoFrame would be kept.
+// readHeaderTimeout is not supported by ghttp.ServerConfig, it is enforced by listener of entry.
+type serverConfig struct {
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	maxBodyBytes      int64
+	keepAlive         *bool
+}
+
+// apply timeouts and limits which were set to ghttp.Server.
+func (c serverConfig) apply(server *ghttp.Server) {
+	if c.readTimeout > 0 {
+		server.SetReadTimeout(c.readTimeout)
+	}
+	if c.writeTimeout > 0 {
+		server.SetWriteTimeout(c.writeTimeout)
+	}
+	if c.idleTimeout > 0 {
+		server.SetIdleTimeout(c.idleTimeout)
+	}
+	if c.maxHeaderBytes > 0 {
+		server.SetMaxHeaderBytes(c.maxHeaderBytes)
+	}
+	if c.maxBodyBytes > 0 {
+		server.SetClientMaxBodySize(c.maxBodyBytes)
+	}
+	if c.keepAlive != nil {
+		server.SetKeepAlive(*c.keepAlive)
+	}
+}
+
+// applyHttp apply timeouts and limits which were set to http.Server, default value of http.Server would be used if missing.
+func (c serverConfig) applyHttp(server *http.Server) {
+	server.ReadTimeout = c.readTimeout
+	server.ReadHeaderTimeout = c.readHeaderTimeout
+	server.WriteTimeout = c.writeTimeout
+	server.IdleTimeout = c.idleTimeout
+	server.MaxHeaderBytes = c.maxHeaderBytes
+
+	if c.maxBodyBytes > 0 {
+		server.Handler = http.MaxBytesHandler(server.Handler, c.maxBodyBytes)
+	}
+	if c.keepAlive != nil {
+		server.SetKeepAlivesEnabled(*c.keepAlive)
+	}
+}
+
+// toMap returns timeouts and limits which were set for marshalling.
+func (c serverConfig) toMap() map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for k, v := range map[string]int64{
+		"readTimeoutMs":       c.readTimeout.Milliseconds(),
+		"readHeaderTimeoutMs": c.readHeaderTimeout.Milliseconds(),
+		"writeTimeoutMs":      c.writeTimeout.Milliseconds(),
+		"idleTimeoutMs":       c.idleTimeout.Milliseconds(),
+		"maxHeaderBytes":      int64(c.maxHeaderBytes),
+		"maxBodyBytes":        c.maxBodyBytes,
+	} {
+		if v > 0 {
+			res[k] = v
+		}
+	}
+
+	if c.keepAlive != nil {
+		res["keepAlive"] = *c.keepAlive
+	}
+
+	return res
+}
+
+const (
+	// connAwaitHeader means request header is being read
+	connAwaitHeader = iota
+	// connInRequest means request is being served
+	connInRequest
+	// connIdle means connection is waiting for next request with keep-alive
+	connIdle
+)
+
+// headerTimeoutConn wraps net.Conn and closes it if request header is not read within timeout.
+//
+// Timer starts once connection is accepted, or first byte of next request arrives on idle connection,
+// and stops once request is handed over to handler of entry.
+type headerTimeoutConn struct {
+	net.Conn
+	timeout    time.Duration
+	lock       sync.Mutex
+	state      int
+	generation int
+	closeOnce  sync.Once
+	onClose    func()
+}
+
+// newHeaderTimeoutConn wraps conn and starts timer of reading first request header.
+func newHeaderTimeoutConn(conn net.Conn, timeout time.Duration, onClose func()) *headerTimeoutConn {
+	c := &headerTimeoutConn{
+		Conn:    conn,
+		timeout: timeout,
+		onClose: onClose,
+	}
+
+	c.lock.Lock()
+	c.awaitHeader()
+	c.lock.Unlock()
+
+	return c
+}
+
+// awaitHeader starts timer of reading request header, must be called with lock held.
+func (c *headerTimeoutConn) awaitHeader() {
+	c.state = connAwaitHeader
+	c.generation++
+	generation := c.generation
+
+	time.AfterFunc(c.timeout, func() {
+		c.lock.Lock()
+		expired := c.state == connAwaitHeader && c.generation == generation
+		c.lock.Unlock()
+
+		if expired {
+			c.Close()
+		}
+	})
+}
+
+// Read starts timer of reading request header if bytes of next request arrive on idle connection.
+func (c *headerTimeoutConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.lock.Lock()
+		if c.state == connIdle {
+			c.awaitHeader()
+		}
+		c.lock.Unlock()
+	}
+
+	return n, err
+}
+
+// startRequest stops timer since request header was read, returns function which marks connection idle.
+func (c *headerTimeoutConn) startRequest() func() {
+	c.lock.Lock()
+	c.state = connInRequest
+	c.generation++
+	c.lock.Unlock()
+
+	return func() {
+		c.lock.Lock()
+		c.state = connIdle
+		c.lock.Unlock()
+	}
+}
+
+// Close closes connection.
+func (c *headerTimeoutConn) Close() error {
+	c.closeOnce.Do(func() {
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+
+	return c.Conn.Close()
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBootServer_Validate(t *testing.T) {
+	assert.Nil(t, (&BootServer{}).validate())
+	assert.Nil(t, (&BootServer{ReadTimeoutMs: 1000, MaxBodyBytes: 1024}).validate())
+	assert.NotNil(t, (&BootServer{WriteTimeoutMs: -1}).validate())
+	assert.NotNil(t, (&BootServer{MaxBodyBytes: -1}).validate())
+	assert.NotNil(t, (&BootServer{ReadHeaderTimeoutMs: -1}).validate())
+}
+
+func TestRegisterGfEntryYAML_Server(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-server
+    port: 8089
+    enabled: true
+    server:
+      readTimeoutMs: 1000
+      readHeaderTimeoutMs: 500
+      idleTimeoutMs: 2000
+      maxHeaderBytes: 4096
+      maxBodyBytes: 1024
+      keepAlive: false
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-server"].(*GfEntry)
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.Equal(t, time.Second, entry.serverConfig.readTimeout)
+	assert.Equal(t, 500*time.Millisecond, entry.serverConfig.readHeaderTimeout)
+	assert.Zero(t, entry.serverConfig.writeTimeout)
+	assert.Equal(t, 2*time.Second, entry.serverConfig.idleTimeout)
+	assert.Equal(t, 4096, entry.serverConfig.maxHeaderBytes)
+	assert.Equal(t, int64(1024), entry.serverConfig.maxBodyBytes)
+	assert.False(t, *entry.serverConfig.keepAlive)
+
+	raw, err := entry.MarshalJSON()
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(raw, &m))
+	assert.Equal(t, map[string]interface{}{
+		"readTimeoutMs":       float64(1000),
+		"readHeaderTimeoutMs": float64(500),
+		"idleTimeoutMs":       float64(2000),
+		"maxHeaderBytes":      float64(4096),
+		"maxBodyBytes":        float64(1024),
+		"keepAlive":           false,
+	}, m["server"])
+}
+
+func TestRegisterGfEntry_ServerDefault(t *testing.T) {
+	entry := RegisterGfEntry(WithName("ut-server-default"))
+	defer rkentry.GlobalAppCtx.RemoveEntry(entry)
+
+	assert.Equal(t, serverConfig{}, entry.serverConfig)
+	assert.Empty(t, entry.serverConfig.toMap())
+}
+
+func TestRegisterGfEntry_ServerKeepsGfConfig(t *testing.T) {
+	// settings of ghttp.Server, like ones loaded from config file of GoFrame, are kept if server config is missing
+	g.Server("ut-server-gf-config").SetKeepAlive(false)
+
+	entry := RegisterGfEntry(
+		WithName("ut-server-gf-config"),
+		WithPort(0),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop))
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-response")
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	resp, err := http.Get("http://127.0.0.1:" + strconv.FormatUint(entry.Port, 10) + "/ut")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.True(t, resp.Close)
+}
+
+func TestGfEntry_ReadHeaderTimeout(t *testing.T) {
+	bootStr := `
+gf:
+  - name: ut-read-header-timeout
+    port: 0
+    enabled: true
+    server:
+      readHeaderTimeoutMs: 200
+      idleTimeoutMs: 5000
+`
+	entry := RegisterGfEntryYAML([]byte(bootStr))["ut-read-header-timeout"].(*GfEntry)
+	entry.Server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-response")
+	})
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	addr := "127.0.0.1:" + strconv.FormatUint(entry.Port, 10)
+
+	// connection is closed if header is not completed within timeout
+	conn, err := net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /ut HTTP/1.1\r\nHost: localhost\r\n"))
+	assert.Nil(t, err)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	// idle keep-alive connection is not affected
+	conn, err = net.Dial("tcp", addr)
+	assert.Nil(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		_, err = conn.Write([]byte("GET /ut HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+		assert.Nil(t, err)
+		resp, err := http.ReadResponse(reader, nil)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "ut-response", string(body))
+
+		time.Sleep(400 * time.Millisecond)
+	}
+}
+
+func TestGfEntry_ReadHeaderTimeout_AllServers(t *testing.T) {
+	sock := path.Join(t.TempDir(), "ut.sock")
+	commonServiceEntry := rkentry.RegisterCommonServiceEntry(&rkentry.BootCommonService{
+		Enabled: true,
+	})
+
+	entry := RegisterGfEntry(
+		WithName("ut-read-header-timeout-all"),
+		WithUnixSocket(sock, 0600),
+		WithManagementPort(8098),
+		WithPlaintextPort(8099, PlaintextModeInternal),
+		WithReadHeaderTimeout(200*time.Millisecond),
+		WithLoggerEntry(rkentry.LoggerEntryNoop),
+		WithEventEntry(rkentry.EventEntryNoop),
+		WithCommonServiceEntry(commonServiceEntry))
+	assert.Nil(t, entry.BootstrapWithError(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	for _, addr := range [][]string{{"unix", sock}, {"tcp", "127.0.0.1:8098"}, {"tcp", "127.0.0.1:8099"}} {
+		conn, err := net.Dial(addr[0], addr[1])
+		assert.Nil(t, err, addr[1])
+		if conn == nil {
+			continue
+		}
+
+		// connection is closed if header is not completed within timeout
+		_, err = conn.Write([]byte("GET " + commonServiceEntry.AlivePath + " HTTP/1.1\r\nHost: localhost\r\n"))
+		assert.Nil(t, err)
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err, addr[1])
+		conn.Close()
+	}
+}
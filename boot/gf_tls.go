@@ -0,0 +1,323 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgf
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BootTLS is bootstrap config of TLS settings applied on top of CertEntry.
+//
+// CertPemPath and KeyPemPath are local files of CertEntry which would be watched for rotation.
+type BootTLS struct {
+	ClientAuth   string   `yaml:"clientAuth" json:"clientAuth"`
+	CaEntry      string   `yaml:"caEntry" json:"caEntry"`
+	MinVersion   string   `yaml:"minVersion" json:"minVersion"`
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites"`
+	CertPemPath  string   `yaml:"certPemPath" json:"certPemPath"`
+	KeyPemPath   string   `yaml:"keyPemPath" json:"keyPemPath"`
+}
+
+// validatePemPath checks whether cert and key files are provided together.
+func (t *BootTLS) validatePemPath() error {
+	if (len(t.CertPemPath) > 0) != (len(t.KeyPemPath) > 0) {
+		return errors.New("tls.certPemPath and tls.keyPemPath should be provided together")
+	}
+
+	return nil
+}
+
+// parseClientAuth convert client auth mode into tls.ClientAuthType.
+func parseClientAuth(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "", "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "requireany":
+		return tls.RequireAnyClientCert, nil
+	case "verifyifgiven":
+		return tls.VerifyClientCertIfGiven, nil
+	case "requireandverify":
+		return tls.RequireAndVerifyClientCert, nil
+	}
+
+	return tls.NoClientCert, fmt.Errorf("invalid tls.clientAuth:%s, [none, request, requireAny, verifyIfGiven, requireAndVerify] are supported", mode)
+}
+
+// parseTlsVersion convert version like 1.2 into tls.VersionTLS12.
+func parseTlsVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "":
+		return 0, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+
+	return 0, fmt.Errorf("invalid tls.minVersion:%s, [1.0, 1.1, 1.2, 1.3] are supported", version)
+}
+
+// parseCipherSuites convert cipher suite names like TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 into ids.
+func parseCipherSuites(names ...string) ([]uint16, error) {
+	supported := make(map[string]uint16)
+	for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		supported[suite.Name] = suite.ID
+	}
+
+	res := make([]uint16, 0)
+	for _, name := range names {
+		id, ok := supported[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls.cipherSuites:%s", name)
+		}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
+
+// newTlsConfig build tls.Config from CertEntry and TLS options.
+func (entry *GfEntry) newTlsConfig() (*tls.Config, error) {
+	conf := &tls.Config{
+		GetCertificate: entry.certReloader.GetCertificate,
+		ClientAuth:     entry.tlsClientAuth,
+		MinVersion:     entry.tlsMinVersion,
+		CipherSuites:   entry.tlsCipherSuites,
+	}
+
+	// CA of cert entry is used to verify client certificates if CA entry is missing
+	caEntry := entry.tlsCaEntry
+	if caEntry == nil {
+		caEntry = entry.CertEntry
+	}
+
+	if caEntry.RootCA != nil {
+		conf.ClientCAs = x509.NewCertPool()
+		conf.ClientCAs.AddCert(caEntry.RootCA)
+	}
+
+	if conf.ClientCAs == nil && (conf.ClientAuth == tls.VerifyClientCertIfGiven || conf.ClientAuth == tls.RequireAndVerifyClientCert) {
+		return nil, errors.New("client certificate verification requires CA, please provide caPath in cert entry")
+	}
+
+	return conf, nil
+}
+
+// certReloader serves certificate of CertEntry and swaps it once cert or key file changed.
+type certReloader struct {
+	entry       *GfEntry
+	certPemPath string
+	keyPemPath  string
+	cert        atomic.Value
+	watcher     *fsnotify.Watcher
+	expiry      prometheus.Gauge
+	closeOnce   sync.Once
+}
+
+// newCertReloader create certReloader with current certificate of CertEntry.
+//
+// Certificate would be watched only if cert and key files were provided with WithTlsPemPath(),
+// and CertEntry was not loaded from embed.FS which is immutable.
+func newCertReloader(entry *GfEntry) *certReloader {
+	reloader := &certReloader{
+		entry: entry,
+	}
+	reloader.cert.Store(entry.CertEntry.Certificate)
+
+	if rkentry.GlobalAppCtx.GetEmbedFS(rkentry.CertEntryType, entry.CertEntry.GetName()) == nil {
+		reloader.certPemPath = entry.tlsCertPemPath
+		reloader.keyPemPath = entry.tlsKeyPemPath
+	}
+
+	if entry.IsPromEnabled() {
+		reloader.expiry = prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "rk",
+			Subsystem:   "gf",
+			Name:        "tls_cert_expiry_timestamp_seconds",
+			Help:        "Expiry time of TLS certificate served by entry in unix seconds.",
+			ConstLabels: prometheus.Labels{"entryName": entry.entryName, "certEntry": entry.CertEntry.GetName()},
+		})
+		if err := entry.PromEntry.Registerer.Register(reloader.expiry); err != nil {
+			if registered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				reloader.expiry = registered.ExistingCollector.(prometheus.Gauge)
+			}
+		}
+		reloader.setExpiry(entry.CertEntry.Certificate)
+	}
+
+	return reloader
+}
+
+// GetCertificate returns latest certificate, used as tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load().(*tls.Certificate), nil
+}
+
+// verify checks whether cert and key files load the same certificate as CertEntry,
+// otherwise rotation of files would replace certificate of CertEntry with an unrelated one.
+func (r *certReloader) verify() error {
+	if len(r.certPemPath) < 1 || len(r.keyPemPath) < 1 {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(absPath(r.certPemPath), absPath(r.keyPemPath))
+	if err != nil {
+		return fmt.Errorf("failed to load tls.certPemPath and tls.keyPemPath, %v", err)
+	}
+
+	current := r.entry.CertEntry.Certificate
+	if len(current.Certificate) < 1 || !bytes.Equal(current.Certificate[0], cert.Certificate[0]) {
+		return fmt.Errorf("certificate of tls.certPemPath:%s does not match certificate of certEntry:%s",
+			r.certPemPath, r.entry.CertEntry.GetName())
+	}
+
+	return nil
+}
+
+// watch starts watching directories of cert and key files.
+// Directories are watched instead of files since rotation usually replaces files, like symlink swap in k8s secret.
+func (r *certReloader) watch() error {
+	if len(r.certPemPath) < 1 || len(r.keyPemPath) < 1 {
+		return nil
+	}
+
+	r.certPemPath, r.keyPemPath = absPath(r.certPemPath), absPath(r.keyPemPath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{filepath.Dir(r.certPemPath), filepath.Dir(r.keyPemPath)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	r.watcher = watcher
+	go r.loop()
+
+	return nil
+}
+
+// loop reloads certificate after file events settled down.
+func (r *certReloader) loop() {
+	var timer *time.Timer
+
+	for {
+		select {
+		case _, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(100*time.Millisecond, r.reload)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.entry.LoggerEntry.Warn("Error occurs while watching certificate files.", zap.Error(err))
+		}
+	}
+}
+
+// reload loads cert and key files, and swaps certificate if it changed.
+func (r *certReloader) reload() {
+	certPem, err := os.ReadFile(r.certPemPath)
+	if err != nil {
+		r.entry.LoggerEntry.Warn("Failed to read certificate file, keep serving old one.", zap.Error(err))
+		return
+	}
+	keyPem, err := os.ReadFile(r.keyPemPath)
+	if err != nil {
+		r.entry.LoggerEntry.Warn("Failed to read key file, keep serving old one.", zap.Error(err))
+		return
+	}
+
+	cert, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		// files may be partially written, wait for next event
+		r.entry.LoggerEntry.Warn("Failed to parse certificate, keep serving old one.", zap.Error(err))
+		return
+	}
+
+	old := r.cert.Load().(*tls.Certificate)
+	if len(old.Certificate) > 0 && bytes.Equal(old.Certificate[0], cert.Certificate[0]) {
+		return
+	}
+
+	r.cert.Store(&cert)
+	r.setExpiry(&cert)
+
+	event := r.entry.EventEntry.Start(
+		"RotateCert",
+		rkquery.WithEntryName(r.entry.GetName()),
+		rkquery.WithEntryType(r.entry.GetType()))
+	event.AddPayloads(
+		zap.String("certEntry", r.entry.CertEntry.GetName()),
+		zap.String("certPemPath", r.certPemPath),
+		zap.String("keyPemPath", r.keyPemPath))
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		event.AddPayloads(zap.Time("notAfter", leaf.NotAfter))
+	}
+	r.entry.EventEntry.Finish(event)
+
+	r.entry.LoggerEntry.Info("Certificate rotated.", zap.String("certPemPath", r.certPemPath))
+}
+
+// setExpiry update expiry gauge with leaf certificate.
+func (r *certReloader) setExpiry(cert *tls.Certificate) {
+	if r.expiry == nil || cert == nil || len(cert.Certificate) < 1 {
+		return
+	}
+
+	if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+		r.expiry.Set(float64(leaf.NotAfter.Unix()))
+	}
+}
+
+// close stops watching files.
+func (r *certReloader) close() {
+	r.closeOnce.Do(func() {
+		if r.watcher != nil {
+			r.watcher.Close()
+		}
+	})
+}
+
+// absPath resolve relative path with working directory, the same as rkentry.CertEntry.
+func absPath(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, p)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/auth"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
 )
 
 // Middleware validate bellow authorization.
@@ -32,7 +33,7 @@ func Middleware(opts ...rkmidauth.Option) ghttp.HandlerFunc {
 			for k, v := range beforeCtx.Output.HeadersToReturn {
 				ctx.Response.Header().Set(k, v)
 			}
-			ctx.Response.WriteStatus(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkgfctx.WriteError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfclient is an outbound HTTP client built on gclient which propagates trace context and request id,
+// logs requests with request logger, records client side metrics and retries with backoff
+package rkgfclient
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/rookie-ninja/rk-logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// attemptKey is key of attempt number of request in context
+type attemptKey struct{}
+
+// New creates Client with options.
+func New(opts ...Option) *Client {
+	set := newOptionSet(opts...)
+
+	client := gclient.New()
+	client.SetPrefix(set.baseUrl)
+	client.SetHeaderMap(set.header)
+	if set.timeout > 0 {
+		client.SetTimeout(set.timeout)
+	}
+	client.Use(set.handle)
+
+	return &Client{
+		client: client,
+		set:    set,
+	}
+}
+
+// Middleware returns gclient.HandlerFunc which could be added into gclient.Client with Use().
+//
+// Request should be sent with context of *ghttp.Request, like gclient.New().Use(rkgfclient.Middleware()).Get(ctx.Context(), url).
+// Retry is not supported by middleware, use Client instead.
+func Middleware(opts ...Option) gclient.HandlerFunc {
+	return newOptionSet(opts...).handle
+}
+
+// Client is an outbound HTTP client built on gclient.Client.
+type Client struct {
+	client *gclient.Client
+	set    *optionSet
+}
+
+// GetName returns name of client.
+func (c *Client) GetName() string {
+	return c.set.name
+}
+
+// GetClient returns underlying gclient.Client.
+func (c *Client) GetClient() *gclient.Client {
+	return c.client
+}
+
+// Get sends GET request.
+func (c *Client) Get(ctx *ghttp.Request, url string, data ...interface{}) (*gclient.Response, error) {
+	return c.DoRequest(ctx, http.MethodGet, url, data...)
+}
+
+// Post sends POST request.
+func (c *Client) Post(ctx *ghttp.Request, url string, data ...interface{}) (*gclient.Response, error) {
+	return c.DoRequest(ctx, http.MethodPost, url, data...)
+}
+
+// Put sends PUT request.
+func (c *Client) Put(ctx *ghttp.Request, url string, data ...interface{}) (*gclient.Response, error) {
+	return c.DoRequest(ctx, http.MethodPut, url, data...)
+}
+
+// Delete sends DELETE request.
+func (c *Client) Delete(ctx *ghttp.Request, url string, data ...interface{}) (*gclient.Response, error) {
+	return c.DoRequest(ctx, http.MethodDelete, url, data...)
+}
+
+// DoRequest sends request with trace context and request id of ctx, ctx could be nil for requests outside of handlers.
+//
+// Request of retryable method would be sent again with backoff if it failed with connection error or response code is retryable.
+// Response of last attempt would be returned.
+func (c *Client) DoRequest(ctx *ghttp.Request, method, url string, data ...interface{}) (*gclient.Response, error) {
+	var reqCtx context.Context = context.Background()
+	if ctx != nil {
+		reqCtx = ctx.Context()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.DoRequest(context.WithValue(reqCtx, attemptKey{}, attempt), method, url, data...)
+
+		statusCode := 0
+		if resp != nil && resp.Response != nil {
+			statusCode = resp.StatusCode
+		}
+
+		if attempt >= c.set.retryCount || !c.set.shouldRetry(method, statusCode, err) {
+			return resp, err
+		}
+
+		_ = resp.Close()
+
+		select {
+		case <-reqCtx.Done():
+			return nil, reqCtx.Err()
+		case <-time.After(c.set.backoff(attempt)):
+		}
+	}
+}
+
+// handle is gclient middleware which instruments every attempt of request.
+func (set *optionSet) handle(c *gclient.Client, req *http.Request) (*gclient.Response, error) {
+	ctx := req.Context()
+	name := "gclient." + set.name
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+
+	newCtx, span := rkgfctx.NewTraceSpanFromCtx(ctx, name)
+	span.SetAttributes(
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url", req.URL.String()),
+		attribute.Int("http.resend_count", attempt))
+	req = req.WithContext(newCtx)
+
+	// propagate trace context and request id
+	if propagator := rkgfctx.GetTracerPropagatorFromCtx(ctx); propagator != nil {
+		propagator.Inject(newCtx, propagation.HeaderCarrier(req.Header))
+	}
+	if requestId := rkgfctx.GetRequestIdFromCtx(ctx); len(requestId) > 0 && len(req.Header.Get(rkmid.HeaderRequestId)) < 1 {
+		req.Header.Set(rkmid.HeaderRequestId, requestId)
+	}
+
+	startTime := time.Now()
+	resp, err := c.Next(req)
+	elapsed := time.Since(startTime)
+
+	event := rkgfctx.GetEventFromCtx(ctx)
+
+	values := []string{set.GetEntryName(), set.GetEntryType(), set.name, req.Method, req.URL.Host}
+	set.histogram.WithLabelValues(values...).Observe(elapsed.Seconds())
+
+	statusCode := 0
+	if err != nil {
+		event.AddErr(err)
+		span.RecordError(err)
+		set.errCounter.WithLabelValues(values...).Inc()
+	} else if resp != nil && resp.Response != nil {
+		statusCode = resp.StatusCode
+		span.SetAttributes(attribute.Int("http.status_code", statusCode))
+		set.resCode.WithLabelValues(append(values, strconv.Itoa(statusCode))...).Inc()
+	}
+	rkgfctx.EndTraceSpanFromCtx(newCtx, span, err == nil && statusCode < http.StatusInternalServerError)
+
+	logger := rkgfctx.GetLoggerFromCtx(ctx)
+	if logger == rklogger.NoopLogger {
+		logger = set.logger
+	}
+
+	fields := []zap.Field{
+		zap.String("client", set.name),
+		zap.String("method", req.Method),
+		zap.String("url", req.URL.String()),
+		zap.Int("attempt", attempt),
+		zap.Duration("elapsed", elapsed),
+	}
+
+	if err != nil {
+		logger.Error("Failed to send HTTP request", append(fields, zap.Error(err))...)
+	} else {
+		logger.Info("Sent HTTP request", append(fields, zap.Int("resCode", statusCode))...)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfclient
+
+import (
+	"context"
+	"errors"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downstream is a fake remote service which replies with status codes in order and records headers of requests.
+type downstream struct {
+	lock    sync.Mutex
+	codes   []int
+	headers []http.Header
+	server  *httptest.Server
+}
+
+func newDownstream(codes ...int) *downstream {
+	d := &downstream{codes: codes}
+	d.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		code := http.StatusOK
+		if len(d.headers) < len(d.codes) {
+			code = d.codes[len(d.headers)]
+		}
+		d.headers = append(d.headers, r.Header.Clone())
+		w.WriteHeader(code)
+	}))
+
+	return d
+}
+
+func (d *downstream) attempts() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.headers)
+}
+
+func TestToOptions(t *testing.T) {
+	config := &BootConfig{
+		Name:      "ut-client",
+		BaseUrl:   "http://ut-host",
+		TimeoutMs: 1000,
+		Header:    map[string]string{"ut-key": "ut-value"},
+	}
+	config.Retry.Count = 2
+	config.Retry.InitialIntervalMs = 10
+	config.Retry.StatusCodes = []int{http.StatusTooManyRequests, http.StatusNotFound}
+	config.Retry.Methods = []string{"post"}
+
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type", zap.NewExample(), prometheus.NewRegistry())...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, "ut-client", set.name)
+	assert.Equal(t, "http://ut-host", set.baseUrl)
+	assert.Equal(t, time.Second, set.timeout)
+	assert.Equal(t, "ut-value", set.header["ut-key"])
+	assert.Equal(t, 2, set.retryCount)
+	assert.Equal(t, 10*time.Millisecond, set.retryInterval)
+	assert.True(t, set.shouldRetry(http.MethodPost, http.StatusTooManyRequests, nil))
+	assert.False(t, set.shouldRetry(http.MethodPost, http.StatusServiceUnavailable, nil))
+	// only 5xx and 429 are retryable
+	assert.False(t, set.shouldRetry(http.MethodPost, http.StatusNotFound, nil))
+	assert.False(t, set.shouldRetry(http.MethodGet, http.StatusTooManyRequests, nil))
+}
+
+func TestOptionSet_ShouldRetry(t *testing.T) {
+	set := newOptionSet(WithRegisterer(prometheus.NewRegistry()))
+
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	// idempotent methods
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete} {
+		assert.True(t, set.shouldRetry(method, http.StatusServiceUnavailable, nil))
+		assert.True(t, set.shouldRetry(method, 0, connErr))
+	}
+
+	// non-idempotent methods
+	assert.False(t, set.shouldRetry(http.MethodPost, http.StatusServiceUnavailable, nil))
+	assert.False(t, set.shouldRetry(http.MethodPatch, 0, connErr))
+
+	// errors other than connection errors
+	assert.False(t, set.shouldRetry(http.MethodGet, 0, errors.New("ut-error")))
+	assert.False(t, set.shouldRetry(http.MethodGet, 0, &url.Error{Op: "Get", URL: "/ut", Err: context.Canceled}))
+	assert.False(t, set.shouldRetry(http.MethodGet, http.StatusInternalServerError, nil))
+}
+
+func TestOptionSet_Backoff(t *testing.T) {
+	set := newOptionSet(
+		WithRetry(5, 10*time.Millisecond, 50*time.Millisecond),
+		WithRegisterer(prometheus.NewRegistry()))
+
+	assert.Equal(t, 10*time.Millisecond, set.backoff(0))
+	assert.Equal(t, 20*time.Millisecond, set.backoff(1))
+	assert.Equal(t, 40*time.Millisecond, set.backoff(2))
+	assert.Equal(t, 50*time.Millisecond, set.backoff(3))
+	assert.Equal(t, 50*time.Millisecond, set.backoff(10))
+}
+
+func TestClient_DoRequest_WithRetry(t *testing.T) {
+	remote := newDownstream(http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer remote.server.Close()
+
+	registry := prometheus.NewRegistry()
+	core, logs := observer.New(zap.InfoLevel)
+	client := New(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithName("ut-client"),
+		WithBaseUrl(remote.server.URL),
+		WithLogger(zap.New(core)),
+		WithRegisterer(registry),
+		WithRetry(3, time.Millisecond, 5*time.Millisecond))
+	assert.Equal(t, "ut-client", client.GetName())
+	assert.NotNil(t, client.GetClient())
+
+	resp, err := client.Get(nil, "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, resp.Close())
+
+	assert.Equal(t, 3, remote.attempts())
+	assert.Equal(t, 3, logs.FilterMessage("Sent HTTP request").Len())
+	assert.Equal(t, 1, logs.FilterField(zap.Int("attempt", 2)).Len())
+	assert.Equal(t, 3, testutil.CollectAndCount(registry, "rk_gclient_resCode"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gclient_elapsedSecond"))
+}
+
+func TestClient_DoRequest_WithoutRetry(t *testing.T) {
+	remote := newDownstream(http.StatusServiceUnavailable)
+	defer remote.server.Close()
+
+	client := New(WithBaseUrl(remote.server.URL), WithRegisterer(prometheus.NewRegistry()))
+
+	resp, err := client.Post(nil, "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, remote.attempts())
+}
+
+func TestClient_DoRequest_WithPost(t *testing.T) {
+	remote := newDownstream(http.StatusServiceUnavailable)
+	defer remote.server.Close()
+
+	client := New(
+		WithBaseUrl(remote.server.URL),
+		WithRegisterer(prometheus.NewRegistry()),
+		WithRetry(3, time.Millisecond, time.Millisecond))
+
+	// POST is not retried by default
+	resp, err := client.Post(nil, "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Nil(t, resp.Close())
+	assert.Equal(t, 1, remote.attempts())
+
+	// POST is retried if provided explicitly
+	remote = newDownstream(http.StatusServiceUnavailable)
+	defer remote.server.Close()
+
+	client = New(
+		WithBaseUrl(remote.server.URL),
+		WithRegisterer(prometheus.NewRegistry()),
+		WithRetry(3, time.Millisecond, time.Millisecond),
+		WithRetryOnMethod(http.MethodPost))
+
+	resp, err = client.Post(nil, "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, resp.Close())
+	assert.Equal(t, 2, remote.attempts())
+}
+
+func TestClient_DoRequest_WithError(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	core, logs := observer.New(zap.InfoLevel)
+	client := New(
+		WithBaseUrl("http://127.0.0.1:1"),
+		WithLogger(zap.New(core)),
+		WithRegisterer(registry),
+		WithRetry(1, time.Millisecond, time.Millisecond))
+
+	_, err := client.Delete(nil, "/ut")
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, logs.FilterMessage("Failed to send HTTP request").Len())
+	assert.Equal(t, float64(2), testutil.ToFloat64(client.set.errCounter))
+}
+
+func TestClient_DoRequest_WithRequest(t *testing.T) {
+	remote := newDownstream()
+	defer remote.server.Close()
+
+	client := New(WithBaseUrl(remote.server.URL), WithRegisterer(prometheus.NewRegistry()))
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8095)
+	server.SetDumpRouterMap(false)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		ctx.Response.Header().Set(rkgfctx.RequestIdKey, "ut-request-id")
+		ctx.SetCtxVar(rkmid.PropagatorKey, propagation.TraceContext{})
+		ctx.Request = ctx.Request.WithContext(trace.ContextWithSpanContext(ctx.Request.Context(), spanCtx))
+
+		resp, err := client.Put(ctx, "/ut")
+		assert.Nil(t, err)
+		assert.Nil(t, resp.Close())
+	})
+	assert.Nil(t, server.Start())
+	defer server.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+	resp, err := gclient.New().Get(context.TODO(), "http://127.0.0.1:8095/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, 1, remote.attempts())
+	assert.Equal(t, "ut-request-id", remote.headers[0].Get(rkmid.HeaderRequestId))
+	assert.Contains(t, remote.headers[0].Get("traceparent"), spanCtx.TraceID().String())
+}
+
+func TestMiddleware(t *testing.T) {
+	remote := newDownstream()
+	defer remote.server.Close()
+
+	registry := prometheus.NewRegistry()
+	client := gclient.New().Use(Middleware(WithRegisterer(registry)))
+
+	resp, err := client.Get(context.TODO(), remote.server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gclient_resCode"))
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfclient
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-logger"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// MetricsNameElapsedSecond records duration of outbound request
+	MetricsNameElapsedSecond = "elapsedSecond"
+	// MetricsNameResCode records response code of outbound request
+	MetricsNameResCode = "resCode"
+	// MetricsNameError records outbound requests failed without response
+	MetricsNameError = "error"
+)
+
+// labelKeys are labels of prometheus metrics
+var labelKeys = []string{
+	"entryName",
+	"entryType",
+	"client",
+	"method",
+	"host",
+}
+
+// BootConfig for YAML
+type BootConfig struct {
+	Name      string            `yaml:"name" json:"name"`
+	BaseUrl   string            `yaml:"baseUrl" json:"baseUrl"`
+	TimeoutMs int               `yaml:"timeoutMs" json:"timeoutMs"`
+	Header    map[string]string `yaml:"header" json:"header"`
+	Retry     struct {
+		Count             int      `yaml:"count" json:"count"`
+		InitialIntervalMs int      `yaml:"initialIntervalMs" json:"initialIntervalMs"`
+		MaxIntervalMs     int      `yaml:"maxIntervalMs" json:"maxIntervalMs"`
+		StatusCodes       []int    `yaml:"statusCodes" json:"statusCodes"`
+		Methods           []string `yaml:"methods" json:"methods"`
+	} `yaml:"retry" json:"retry"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for client implementation
+type optionSet struct {
+	entryName     string
+	entryType     string
+	name          string
+	baseUrl       string
+	timeout       time.Duration
+	header        map[string]string
+	logger        *zap.Logger
+	registerer    prometheus.Registerer
+	histogram     *prometheus.HistogramVec
+	resCode       *prometheus.CounterVec
+	errCounter    *prometheus.CounterVec
+	retryCount    int
+	retryInterval time.Duration
+	retryMax      time.Duration
+	retryOnStatus map[int]bool
+	retryOnMethod map[string]bool
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:     "fake-entry",
+		entryType:     "",
+		name:          "default",
+		header:        map[string]string{},
+		logger:        rklogger.NoopLogger,
+		registerer:    prometheus.DefaultRegisterer,
+		retryInterval: 100 * time.Millisecond,
+		retryMax:      2 * time.Second,
+		retryOnStatus: map[int]bool{
+			http.StatusBadGateway:         true,
+			http.StatusServiceUnavailable: true,
+			http.StatusGatewayTimeout:     true,
+		},
+		retryOnMethod: map[string]bool{
+			http.MethodGet:     true,
+			http.MethodHead:    true,
+			http.MethodOptions: true,
+			http.MethodPut:     true,
+			http.MethodDelete:  true,
+		},
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	if set.retryMax < set.retryInterval {
+		set.retryMax = set.retryInterval
+	}
+
+	set.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rk",
+		Subsystem: "gclient",
+		Name:      MetricsNameElapsedSecond,
+		Help:      "Histogram of outbound requests sent by gclient",
+		Buckets:   prometheus.DefBuckets,
+	}, labelKeys)
+	if existing, ok := register(set.registerer, set.histogram).(*prometheus.HistogramVec); ok {
+		set.histogram = existing
+	}
+
+	set.resCode = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rk",
+		Subsystem: "gclient",
+		Name:      MetricsNameResCode,
+		Help:      "Counter of response codes of outbound requests sent by gclient",
+	}, append(labelKeys, "resCode"))
+	if existing, ok := register(set.registerer, set.resCode).(*prometheus.CounterVec); ok {
+		set.resCode = existing
+	}
+
+	set.errCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rk",
+		Subsystem: "gclient",
+		Name:      MetricsNameError,
+		Help:      "Counter of outbound requests sent by gclient failed without response",
+	}, labelKeys)
+	if existing, ok := register(set.registerer, set.errCounter).(*prometheus.CounterVec); ok {
+		set.errCounter = existing
+	}
+
+	return set
+}
+
+// register registers collector, existing collector would be returned if registered by another client.
+func register(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	if err := registerer.Register(collector); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			return are.ExistingCollector
+		}
+	}
+
+	return nil
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// shouldRetry determine whether request should be sent again based on method, error and status code.
+//
+// Only requests of retryable methods would be sent again, if they failed with connection error or retryable status code.
+func (set *optionSet) shouldRetry(method string, statusCode int, err error) bool {
+	if !set.retryOnMethod[strings.ToUpper(method)] {
+		return false
+	}
+
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && !errors.Is(err, context.Canceled)
+	}
+
+	return set.retryOnStatus[statusCode]
+}
+
+// backoff returns interval to wait before retry of attempt, interval doubles with every attempt until max interval
+func (set *optionSet) backoff(attempt int) time.Duration {
+	interval := set.retryInterval
+	for i := 0; i < attempt && interval < set.retryMax; i++ {
+		interval *= 2
+	}
+
+	if interval > set.retryMax {
+		interval = set.retryMax
+	}
+
+	return interval
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string, logger *zap.Logger, registerer prometheus.Registerer) []Option {
+	return []Option{
+		WithEntryNameAndType(entryName, entryType),
+		WithName(config.Name),
+		WithBaseUrl(config.BaseUrl),
+		WithTimeout(time.Duration(config.TimeoutMs) * time.Millisecond),
+		WithHeader(config.Header),
+		WithLogger(logger),
+		WithRegisterer(registerer),
+		WithRetry(config.Retry.Count,
+			time.Duration(config.Retry.InitialIntervalMs)*time.Millisecond,
+			time.Duration(config.Retry.MaxIntervalMs)*time.Millisecond),
+		WithRetryOnStatus(config.Retry.StatusCodes...),
+		WithRetryOnMethod(config.Retry.Methods...),
+	}
+}
+
+// Option if for client options while creating client
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithName provide name of client, which is used as label of metrics and name of span.
+func WithName(name string) Option {
+	return func(opt *optionSet) {
+		if len(name) > 0 {
+			opt.name = name
+		}
+	}
+}
+
+// WithBaseUrl provide prefix of urls of requests, like http://localhost:8080.
+func WithBaseUrl(baseUrl string) Option {
+	return func(opt *optionSet) {
+		opt.baseUrl = baseUrl
+	}
+}
+
+// WithTimeout provide timeout of each attempt, default value of gclient would be used if 0.
+func WithTimeout(timeout time.Duration) Option {
+	return func(opt *optionSet) {
+		if timeout > 0 {
+			opt.timeout = timeout
+		}
+	}
+}
+
+// WithHeader provide headers sent with every request.
+func WithHeader(header map[string]string) Option {
+	return func(opt *optionSet) {
+		for k, v := range header {
+			opt.header[k] = v
+		}
+	}
+}
+
+// WithLogger provide zap logger used if request logger is missing in context.
+func WithLogger(logger *zap.Logger) Option {
+	return func(opt *optionSet) {
+		if logger != nil {
+			opt.logger = logger
+		}
+	}
+}
+
+// WithRegisterer provide prometheus.Registerer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(opt *optionSet) {
+		if registerer != nil {
+			opt.registerer = registerer
+		}
+	}
+}
+
+// WithRetry provide max retries of request, interval between retries starts from initialInterval
+// and doubles with every retry until maxInterval.
+func WithRetry(count int, initialInterval, maxInterval time.Duration) Option {
+	return func(opt *optionSet) {
+		if count > 0 {
+			opt.retryCount = count
+		}
+
+		if initialInterval > 0 {
+			opt.retryInterval = initialInterval
+		}
+
+		if maxInterval > 0 {
+			opt.retryMax = maxInterval
+		}
+	}
+}
+
+// WithRetryOnStatus provide status codes of responses to retry, 502, 503 and 504 would be used by default.
+//
+// Only 5xx and 429 are accepted, other status codes would be ignored.
+func WithRetryOnStatus(codes ...int) Option {
+	return func(opt *optionSet) {
+		if len(codes) < 1 {
+			return
+		}
+
+		opt.retryOnStatus = map[int]bool{}
+		for _, code := range codes {
+			if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests {
+				opt.retryOnStatus[code] = true
+			}
+		}
+	}
+}
+
+// WithRetryOnMethod provide methods of requests to retry, GET, HEAD, OPTIONS, PUT and DELETE would be used by default.
+//
+// Non-idempotent methods like POST and PATCH would be retried only if provided explicitly.
+func WithRetryOnMethod(methods ...string) Option {
+	return func(opt *optionSet) {
+		if len(methods) < 1 {
+			return
+		}
+
+		opt.retryOnMethod = map[string]bool{}
+		for _, method := range methods {
+			opt.retryOnMethod[strings.ToUpper(method)] = true
+		}
+	}
+}
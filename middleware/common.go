@@ -9,8 +9,21 @@ package rkgfinter
 import (
 	"github.com/gogf/gf/v2/os/glog"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"sync"
 )
 
+const (
+	// GcodeKey is key of gcode in context of request which is set by gcode middleware and read by prom middleware
+	GcodeKey = "rkGcode"
+	// GcodeEnabledKey is key in context of request which is set by gcode middleware if error of request would be written by it
+	GcodeEnabledKey = "rkGcodeEnabled"
+)
+
+// errorBuilders stores rkerror.ErrorBuilder of each entry keyed by entry name
+var errorBuilders = sync.Map{}
+
 type noopWriter struct{}
 
 func (w noopWriter) Write([]byte) (n int, err error) {
@@ -38,3 +51,40 @@ func (w *zapWriter) Write(in []byte) (n int, err error) {
 
 	return len(in), nil
 }
+
+// SetErrorBuilder set rkerror.ErrorBuilder of entry, error responses written by middlewares of entry would be built with it.
+//
+// Builder of entry would be removed if nil provided.
+func SetErrorBuilder(entryName string, builder rkerror.ErrorBuilder) {
+	if builder == nil {
+		errorBuilders.Delete(entryName)
+		return
+	}
+
+	errorBuilders.Store(entryName, builder)
+}
+
+// GetErrorBuilder returns rkerror.ErrorBuilder of entry, rkmid.GetErrorBuilder() would be returned if missing.
+func GetErrorBuilder(entryName string) rkerror.ErrorBuilder {
+	if v, ok := errorBuilders.Load(entryName); ok {
+		return v.(rkerror.ErrorBuilder)
+	}
+
+	return rkmid.GetErrorBuilder()
+}
+
+// RebuildError rebuild error with rkerror.ErrorBuilder of entry, original error would be returned if entry has no builder.
+//
+// Errors returned from rk-entry middlewares are built with global builder, so we need to rebuild them.
+func RebuildError(entryName string, err rkerror.ErrorInterface) rkerror.ErrorInterface {
+	if err == nil {
+		return nil
+	}
+
+	v, ok := errorBuilders.Load(entryName)
+	if !ok {
+		return err
+	}
+
+	return v.(rkerror.ErrorBuilder).New(err.Code(), err.Message(), err.Details()...)
+}
@@ -5,9 +5,35 @@
 
 package rkgfinter
 
-import "testing"
+import (
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
 
 func TestNewNoopGLogger(t *testing.T) {
 	log := NewNoopGLogger()
 	log.Write([]byte{})
 }
+
+func TestErrorBuilder(t *testing.T) {
+	defer SetErrorBuilder("ut-entry", nil)
+
+	// without builder of entry
+	assert.Equal(t, rkmid.GetErrorBuilder(), GetErrorBuilder("ut-entry"))
+	err := rkerror.NewErrorBuilderGoogle().New(http.StatusUnauthorized, "ut-msg", "ut-detail")
+	assert.Equal(t, err, RebuildError("ut-entry", err))
+	assert.Nil(t, RebuildError("ut-entry", nil))
+
+	// with builder of entry
+	builder := rkerror.NewErrorBuilderAMZN()
+	SetErrorBuilder("ut-entry", builder)
+	assert.Equal(t, builder, GetErrorBuilder("ut-entry"))
+	res := RebuildError("ut-entry", err)
+	assert.IsType(t, &rkerror.ErrorAMZN{}, res)
+	assert.Equal(t, http.StatusUnauthorized, res.Code())
+	assert.Equal(t, "ut-msg", res.Message())
+	assert.Equal(t, []interface{}{"ut-detail"}, res.Details())
+}
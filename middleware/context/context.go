@@ -8,10 +8,13 @@ package rkgfctx
 
 import (
 	"context"
+	"crypto/x509"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/golang-jwt/jwt/v4"
 	rkcursor "github.com/rookie-ninja/rk-entry/v2/cursor"
+	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
 	"github.com/rookie-ninja/rk-logger"
 	"github.com/rookie-ninja/rk-query"
 	otelcodes "go.opentelemetry.io/otel/codes"
@@ -147,6 +150,72 @@ func GetEntryName(ctx *ghttp.Request) string {
 	return ""
 }
 
+// GetErrorBuilder extract rkerror.ErrorBuilder of entry which handles request.
+func GetErrorBuilder(ctx *ghttp.Request) rkerror.ErrorBuilder {
+	return rkgfinter.GetErrorBuilder(GetEntryName(ctx))
+}
+
+// WriteError write error response with error model of entry which handles request.
+//
+// RFC 7807 problem details would be filled with instance, request id and trace id.
+// Error would be wrapped in envelope with code, message and details as data if response middleware enabled.
+func WriteError(ctx *ghttp.Request, err rkerror.ErrorInterface) {
+	if ctx == nil || err == nil {
+		return
+	}
+
+	resp := rkgfinter.RebuildError(GetEntryName(ctx), err)
+
+	if envelope := GetEnvelope(ctx); envelope != nil {
+		var data interface{}
+		if details := resp.Details(); len(details) > 0 {
+			data = details
+		}
+
+		WriteEnvelope(ctx, resp.Code(), resp.Message(), data)
+		return
+	}
+
+	if problem, ok := resp.(*rkgfinter.ErrorProblem); ok {
+		resp = problem.WithRequest(ctx.Request, ctx.Response.Header())
+	}
+
+	ctx.Response.Header().Set(rkmid.HeaderContentType, rkgfinter.ErrorContentType(resp))
+	ctx.Response.WriteStatus(resp.Code(), resp)
+}
+
+// GetEnvelope extract envelope of response middleware, nil would be returned if response middleware disabled.
+func GetEnvelope(ctx *ghttp.Request) *rkgfinter.Envelope {
+	if ctx == nil {
+		return nil
+	}
+
+	if raw := ctx.GetCtxVar(rkgfinter.EnvelopeKey).Interface(); raw != nil {
+		if envelope, ok := raw.(*rkgfinter.Envelope); ok {
+			return envelope
+		}
+	}
+
+	return nil
+}
+
+// WriteEnvelope write response wrapped in envelope with request id and trace id.
+//
+// Default envelope would be used if response middleware disabled.
+func WriteEnvelope(ctx *ghttp.Request, code int, message string, data interface{}) {
+	if ctx == nil {
+		return
+	}
+
+	envelope := GetEnvelope(ctx)
+	if envelope == nil {
+		envelope = rkgfinter.NewEnvelope()
+	}
+
+	ctx.Response.WriteHeader(code)
+	ctx.Response.WriteJson(envelope.Wrap(code, message, data, GetRequestId(ctx), GetTraceId(ctx)))
+}
+
 // GetTraceSpan extract the call-scoped span from context.
 func GetTraceSpan(ctx *ghttp.Request) trace.Span {
 	_, span := noopTracerProvider.Tracer("rk-trace-noop").Start(context.TODO(), "noop-span")
@@ -264,3 +333,49 @@ func GetCsrfToken(ctx *ghttp.Request) string {
 
 	return ""
 }
+
+// GetClientCert return verified client certificate of mutual TLS if exists
+func GetClientCert(ctx *ghttp.Request) *x509.Certificate {
+	if ctx == nil || ctx.Request == nil || ctx.Request.TLS == nil {
+		return nil
+	}
+
+	// only certificates verified with configured CA are trusted
+	chains := ctx.Request.TLS.VerifiedChains
+	if len(chains) < 1 || len(chains[0]) < 1 {
+		return nil
+	}
+
+	return chains[0][0]
+}
+
+// GetClientCertSubject return subject of verified client certificate, empty string if missing
+func GetClientCertSubject(ctx *ghttp.Request) string {
+	if cert := GetClientCert(ctx); cert != nil {
+		return cert.Subject.String()
+	}
+
+	return ""
+}
+
+// GetClientCertSANs return subject alternative names of verified client certificate
+// including DNS names, email addresses, IP addresses and URIs
+func GetClientCertSANs(ctx *ghttp.Request) []string {
+	res := make([]string, 0)
+
+	cert := GetClientCert(ctx)
+	if cert == nil {
+		return res
+	}
+
+	res = append(res, cert.DNSNames...)
+	res = append(res, cert.EmailAddresses...)
+	for i := range cert.IPAddresses {
+		res = append(res, cert.IPAddresses[i].String())
+	}
+	for i := range cert.URIs {
+		res = append(res, cert.URIs[i].String())
+	}
+
+	return res
+}
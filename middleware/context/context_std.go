@@ -0,0 +1,219 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfctx
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/golang-jwt/jwt/v4"
+	rkcursor "github.com/rookie-ninja/rk-entry/v2/cursor"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-logger"
+	"github.com/rookie-ninja/rk-query"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// Functions in this file take context.Context instead of *ghttp.Request, which could be used in service and DAO layers.
+//
+// Values are looked up in context first, which contains values injected by middlewares before handler is called,
+// and then in *ghttp.Request retrieved by ghttp.RequestFromCtx().
+
+// ctxValue returns value of key injected by middlewares.
+func ctxValue(ctx context.Context, key interface{}) interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	if v := ctx.Value(key); v != nil {
+		return v
+	}
+
+	if req := ghttp.RequestFromCtx(ctx); req != nil {
+		return req.GetCtxVar(key).Interface()
+	}
+
+	return nil
+}
+
+// GetEventFromCtx extract takes the call-scoped EventData from context.Context.
+func GetEventFromCtx(ctx context.Context) rkquery.Event {
+	if raw, ok := ctxValue(ctx, rkmid.EventKey).(rkquery.Event); ok {
+		return raw
+	}
+
+	return noopEvent
+}
+
+// GetLoggerFromCtx extract takes the call-scoped zap logger from context.Context.
+func GetLoggerFromCtx(ctx context.Context) *zap.Logger {
+	if raw, ok := ctxValue(ctx, rkmid.LoggerKey).(*zap.Logger); ok {
+		fields := make([]zap.Field, 0)
+		if requestId := GetRequestIdFromCtx(ctx); len(requestId) > 0 {
+			fields = append(fields, zap.String("requestId", requestId))
+		}
+		if traceId := GetTraceIdFromCtx(ctx); len(traceId) > 0 {
+			fields = append(fields, zap.String("traceId", traceId))
+		}
+
+		return raw.With(fields...)
+	}
+
+	return rklogger.NoopLogger
+}
+
+// GetCursorFromCtx create rkcursor.Cursor instance from context.Context.
+func GetCursorFromCtx(ctx context.Context) *rkcursor.Cursor {
+	res := rkcursor.NewCursor(
+		rkcursor.WithLogger(GetLoggerFromCtx(ctx)),
+		rkcursor.WithEvent(GetEventFromCtx(ctx)),
+		rkcursor.WithEntryNameAndType(GetEntryNameFromCtx(ctx), "GoFrameEntry"))
+
+	if pointerCreator != nil {
+		res.Creator = pointerCreator
+	}
+
+	return res
+}
+
+// GetRequestIdFromCtx extract request id from context.Context.
+func GetRequestIdFromCtx(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	return GetRequestId(ghttp.RequestFromCtx(ctx))
+}
+
+// GetTraceIdFromCtx extract trace id from context.Context.
+func GetTraceIdFromCtx(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	return GetTraceId(ghttp.RequestFromCtx(ctx))
+}
+
+// GetEntryNameFromCtx extract entry name from context.Context.
+func GetEntryNameFromCtx(ctx context.Context) string {
+	if raw, ok := ctxValue(ctx, rkmid.EntryNameKey).(string); ok {
+		return raw
+	}
+
+	return ""
+}
+
+// GetTraceSpanFromCtx extract the call-scoped span from context.Context.
+//
+// Span started by NewTraceSpanFromCtx() would be returned if exists.
+func GetTraceSpanFromCtx(ctx context.Context) trace.Span {
+	if ctx == nil {
+		_, span := noopTracerProvider.Tracer("rk-trace-noop").Start(context.TODO(), "noop-span")
+		return span
+	}
+
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		return span
+	}
+
+	if raw, ok := ctxValue(ctx, rkmid.SpanKey).(trace.Span); ok {
+		return raw
+	}
+
+	_, span := noopTracerProvider.Tracer("rk-trace-noop").Start(ctx, "noop-span")
+	return span
+}
+
+// GetTracerFromCtx extract the call-scoped tracer from context.Context.
+func GetTracerFromCtx(ctx context.Context) trace.Tracer {
+	if raw, ok := ctxValue(ctx, rkmid.TracerKey).(trace.Tracer); ok {
+		return raw
+	}
+
+	return noopTracerProvider.Tracer("rk-trace-noop")
+}
+
+// GetTracerProviderFromCtx extract the call-scoped tracer provider from context.Context.
+func GetTracerProviderFromCtx(ctx context.Context) trace.TracerProvider {
+	if raw, ok := ctxValue(ctx, rkmid.TracerProviderKey).(trace.TracerProvider); ok {
+		return raw
+	}
+
+	return noopTracerProvider
+}
+
+// GetTracerPropagatorFromCtx extract takes the call-scoped propagator from context.Context.
+func GetTracerPropagatorFromCtx(ctx context.Context) propagation.TextMapPropagator {
+	if raw, ok := ctxValue(ctx, rkmid.PropagatorKey).(propagation.TextMapPropagator); ok {
+		return raw
+	}
+
+	return nil
+}
+
+// InjectSpanToHttpRequestFromCtx inject span in context.Context to http request
+func InjectSpanToHttpRequestFromCtx(ctx context.Context, req *http.Request) {
+	if req == nil {
+		return
+	}
+
+	newCtx := trace.ContextWithRemoteSpanContext(req.Context(), GetTraceSpanFromCtx(ctx).SpanContext())
+
+	if propagator := GetTracerPropagatorFromCtx(ctx); propagator != nil {
+		propagator.Inject(newCtx, propagation.HeaderCarrier(req.Header))
+	}
+}
+
+// spanNameKey is key of span name in context.Context returned by NewTraceSpanFromCtx()
+type spanNameKey struct{}
+
+// NewTraceSpanFromCtx start a new span as child of span in context.Context, new context.Context contains span would be returned.
+//
+// Span could be ended with EndTraceSpanFromCtx().
+func NewTraceSpanFromCtx(ctx context.Context, name string) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	newCtx, span := GetTracerFromCtx(ctx).Start(trace.ContextWithSpan(ctx, GetTraceSpanFromCtx(ctx)), name)
+
+	GetEventFromCtx(ctx).StartTimer(name)
+
+	return context.WithValue(newCtx, spanNameKey{}, name), span
+}
+
+// EndTraceSpanFromCtx end span and timer of event started by NewTraceSpanFromCtx().
+//
+// ctx should be the one returned by NewTraceSpanFromCtx().
+func EndTraceSpanFromCtx(ctx context.Context, span trace.Span, success bool) {
+	if ctx != nil {
+		if name, ok := ctx.Value(spanNameKey{}).(string); ok {
+			GetEventFromCtx(ctx).EndTimer(name)
+		}
+	}
+
+	EndTraceSpan(nil, span, success)
+}
+
+// GetJwtTokenFromCtx return jwt.Token in context.Context if exists
+func GetJwtTokenFromCtx(ctx context.Context) *jwt.Token {
+	if raw, ok := ctxValue(ctx, rkmid.JwtTokenKey).(*jwt.Token); ok {
+		return raw
+	}
+
+	return nil
+}
+
+// GetCsrfTokenFromCtx return csrf token in context.Context if exists
+func GetCsrfTokenFromCtx(ctx context.Context) string {
+	if raw, ok := ctxValue(ctx, rkmid.CsrfTokenKey).(string); ok {
+		return raw
+	}
+
+	return ""
+}
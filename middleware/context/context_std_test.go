@@ -0,0 +1,127 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfctx
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-logger"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFromCtx_WithNilCtx(t *testing.T) {
+	var ctx context.Context
+
+	assert.Equal(t, noopEvent, GetEventFromCtx(ctx))
+	assert.Equal(t, rklogger.NoopLogger, GetLoggerFromCtx(ctx))
+	assert.NotNil(t, GetCursorFromCtx(ctx))
+	assert.Empty(t, GetRequestIdFromCtx(ctx))
+	assert.Empty(t, GetTraceIdFromCtx(ctx))
+	assert.Empty(t, GetEntryNameFromCtx(ctx))
+	assert.NotNil(t, GetTraceSpanFromCtx(ctx))
+	assert.NotNil(t, GetTracerFromCtx(ctx))
+	assert.NotNil(t, GetTracerProviderFromCtx(ctx))
+	assert.Nil(t, GetTracerPropagatorFromCtx(ctx))
+	assert.Nil(t, GetJwtTokenFromCtx(ctx))
+	assert.Empty(t, GetCsrfTokenFromCtx(ctx))
+
+	newCtx, span := NewTraceSpanFromCtx(ctx, "ut-span")
+	assert.NotNil(t, newCtx)
+	assert.NotNil(t, span)
+}
+
+func TestFromCtx_WithValues(t *testing.T) {
+	event := rkquery.NewEventFactory().CreateEventNoop()
+	token := &jwt.Token{}
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+	})
+
+	ctx := context.WithValue(context.TODO(), rkmid.EventKey, event)
+	ctx = context.WithValue(ctx, rkmid.EntryNameKey, "ut-entry")
+	ctx = context.WithValue(ctx, rkmid.JwtTokenKey, token)
+	ctx = context.WithValue(ctx, rkmid.CsrfTokenKey, "ut-csrf")
+	ctx = context.WithValue(ctx, rkmid.TracerProviderKey, noopTracerProvider)
+
+	assert.Equal(t, event, GetEventFromCtx(ctx))
+	assert.Equal(t, "ut-entry", GetEntryNameFromCtx(ctx))
+	assert.Equal(t, token, GetJwtTokenFromCtx(ctx))
+	assert.Equal(t, "ut-csrf", GetCsrfTokenFromCtx(ctx))
+	assert.Equal(t, noopTracerProvider, GetTracerProviderFromCtx(ctx))
+
+	// span in context
+	ctx = trace.ContextWithSpanContext(ctx, spanCtx)
+	assert.Equal(t, spanCtx, GetTraceSpanFromCtx(ctx).SpanContext())
+
+	// child span shares trace id
+	_, span := NewTraceSpanFromCtx(ctx, "ut-span")
+	assert.Equal(t, spanCtx.TraceID(), span.SpanContext().TraceID())
+	EndTraceSpan(nil, span, true)
+}
+
+func TestEndTraceSpanFromCtx(t *testing.T) {
+	event := rkquery.NewEventFactory().CreateEvent()
+	event.SetStartTime(time.Now())
+	ctx := context.WithValue(context.TODO(), rkmid.EventKey, event)
+
+	// span and timer of event are ended together
+	newCtx, span := NewTraceSpanFromCtx(ctx, "ut-span")
+	time.Sleep(10 * time.Millisecond)
+	EndTraceSpanFromCtx(newCtx, span, true)
+	assert.GreaterOrEqual(t, event.GetTimeElapsedMs("ut-span"), int64(10))
+
+	// context without span name
+	EndTraceSpanFromCtx(nil, span, false)
+	EndTraceSpanFromCtx(ctx, span, false)
+}
+
+func TestFromCtx_WithRequest(t *testing.T) {
+	var (
+		requestId string
+		logger    *zap.Logger
+	)
+
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8094)
+	server.SetDumpRouterMap(false)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	server.BindMiddlewareDefault(func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.LoggerKey, zap.NewExample())
+		ctx.Response.Header().Set(RequestIdKey, "ut-request-id")
+		ctx.Middleware.Next()
+	})
+	server.BindHandler("/ut", func(ctx *ghttp.Request) {
+		// business code only receives context.Context
+		func(ctx context.Context) {
+			requestId = GetRequestIdFromCtx(ctx)
+			logger = GetLoggerFromCtx(ctx)
+		}(ctx.Context())
+	})
+	assert.Nil(t, server.Start())
+	defer server.Shutdown()
+
+	time.Sleep(100 * time.Millisecond)
+	resp, err := http.Get("http://127.0.0.1:8094/ut")
+	assert.Nil(t, err)
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, "ut-request-id", requestId)
+	assert.NotNil(t, logger)
+	assert.NotEqual(t, rklogger.NoopLogger, logger)
+}
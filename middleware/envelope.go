@@ -0,0 +1,61 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfinter
+
+import (
+	"github.com/gogf/gf/v2/container/gmap"
+)
+
+const (
+	// EnvelopeKey is key of *Envelope in context of request which is set by response middleware
+	EnvelopeKey = "rkEnvelope"
+	// EnvelopeOmitted is the key of field which should be omitted from envelope
+	EnvelopeOmitted = "-"
+)
+
+// NewEnvelope returns Envelope with default keys, which are code, message, data, requestId and traceId.
+func NewEnvelope() *Envelope {
+	return &Envelope{
+		CodeKey:      "code",
+		MessageKey:   "message",
+		DataKey:      "data",
+		RequestIdKey: "requestId",
+		TraceIdKey:   "traceId",
+	}
+}
+
+// Envelope wraps response of handler and error in unified JSON body.
+//
+// Field with key of EnvelopeOmitted would be omitted.
+type Envelope struct {
+	CodeKey      string `yaml:"code" json:"code"`
+	MessageKey   string `yaml:"message" json:"message"`
+	DataKey      string `yaml:"data" json:"data"`
+	RequestIdKey string `yaml:"requestId" json:"requestId"`
+	TraceIdKey   string `yaml:"traceId" json:"traceId"`
+}
+
+// Wrap returns envelope with fields in order of code, message, data, requestId and traceId.
+func (e *Envelope) Wrap(code int, message string, data interface{}, requestId, traceId string) *gmap.ListMap {
+	res := gmap.NewListMap()
+
+	for _, field := range []struct {
+		key   string
+		value interface{}
+	}{
+		{e.CodeKey, code},
+		{e.MessageKey, message},
+		{e.DataKey, data},
+		{e.RequestIdKey, requestId},
+		{e.TraceIdKey, traceId},
+	} {
+		if len(field.key) > 0 && field.key != EnvelopeOmitted {
+			res.Set(field.key, field.value)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,27 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfinter
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestEnvelope_Wrap(t *testing.T) {
+	envelope := NewEnvelope()
+
+	// with default keys
+	res := envelope.Wrap(http.StatusOK, "ut-msg", "ut-data", "ut-request-id", "ut-trace-id")
+	assert.Equal(t, []interface{}{"code", "message", "data", "requestId", "traceId"}, res.Keys())
+	assert.Equal(t, `{"code":200,"message":"ut-msg","data":"ut-data","requestId":"ut-request-id","traceId":"ut-trace-id"}`, res.String())
+
+	// with custom and omitted keys
+	envelope.DataKey = "result"
+	envelope.TraceIdKey = EnvelopeOmitted
+	res = envelope.Wrap(http.StatusOK, "", nil, "", "")
+	assert.Equal(t, []interface{}{"code", "message", "result", "requestId"}, res.Keys())
+}
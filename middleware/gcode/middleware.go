@@ -0,0 +1,55 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfgcode is a middleware of GoFrame framework for converting gerror returned by handler into rk error model
+package rkgfgcode
+
+import (
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"strconv"
+)
+
+// Middleware converts error of handler into error model of entry with HTTP status mapped from gcode.
+//
+// Mapped gcode would be recorded in event as pair of gcode and counted by prom middleware with label of gcode,
+// mapped HTTP status would be used as resCode by logging and prom middlewares.
+func Middleware(opts ...Option) ghttp.HandlerFunc {
+	set := newOptionSet(opts...)
+
+	return func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.EntryNameKey, set.GetEntryName())
+
+		if set.ShouldIgnore(ctx.URL.Path) {
+			ctx.Middleware.Next()
+			return
+		}
+
+		// typed handler leaves error to gcode middleware, so that mapping of entry would be used
+		ctx.SetCtxVar(rkgfinter.GcodeEnabledKey, true)
+
+		ctx.Middleware.Next()
+
+		err := ctx.GetError()
+		if err == nil {
+			return
+		}
+
+		code := gerror.Code(err)
+		rkgfctx.GetEvent(ctx).AddPair("gcode", strconv.Itoa(code.Code()))
+		ctx.SetCtxVar(rkgfinter.GcodeKey, code.Code())
+
+		details := make([]interface{}, 0)
+		if detail := code.Detail(); detail != nil {
+			details = append(details, detail)
+		}
+
+		ctx.Response.ClearBuffer()
+		rkgfctx.WriteError(ctx, rkgfctx.GetErrorBuilder(ctx).New(set.Status(code.Code()), err.Error(), details...))
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfgcode
+
+import (
+	"context"
+	"errors"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestToOptions(t *testing.T) {
+	// disabled
+	config := &BootConfig{
+		Enabled: false,
+		Mapping: map[int]int{gcode.CodeNotFound.Code(): http.StatusGone},
+	}
+	assert.Empty(t, ToOptions(config, "ut-entry", "ut-type"))
+
+	// enabled
+	config.Enabled = true
+	config.Ignore = []string{"/ut-ignore"}
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type")...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, http.StatusGone, set.Status(gcode.CodeNotFound.Code()))
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+	assert.False(t, set.ShouldIgnore("/ut"))
+}
+
+func TestDefaultStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, DefaultStatus(gcode.CodeNotFound.Code()))
+	assert.Equal(t, http.StatusInternalServerError, DefaultStatus(gcode.CodeNil.Code()))
+	assert.Equal(t, http.StatusInternalServerError, DefaultStatus(10001))
+}
+
+func TestOptionSet_Status(t *testing.T) {
+	set := newOptionSet(WithMapping(10001, http.StatusConflict))
+
+	assert.Equal(t, http.StatusBadRequest, set.Status(gcode.CodeValidationFailed.Code()))
+	assert.Equal(t, http.StatusUnauthorized, set.Status(gcode.CodeNotAuthorized.Code()))
+	assert.Equal(t, http.StatusNotFound, set.Status(gcode.CodeNotFound.Code()))
+	assert.Equal(t, http.StatusConflict, set.Status(10001))
+	// unknown code
+	assert.Equal(t, http.StatusInternalServerError, set.Status(10002))
+}
+
+func TestMiddleware_WithoutError(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.WriteHeader(http.StatusOK)
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithGcode(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-partial")
+		ctx.SetError(gerror.NewCode(gcode.CodeNotFound, "ut-not-found"))
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	body := resp.ReadAllString()
+	assert.Contains(t, body, "ut-not-found")
+	assert.NotContains(t, body, "ut-partial")
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithMappingAndPlainError(t *testing.T) {
+	defer assertNotPanic(t)
+
+	inter := Middleware(WithMapping(10001, http.StatusConflict))
+
+	// custom code
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.SetError(gerror.NewCode(gcode.New(10001, "ut-conflict", nil), "ut-error"))
+	}, inter)
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+
+	// error without code
+	server = startServer(t, func(ctx *ghttp.Request) {
+		ctx.SetError(errors.New("ut-error"))
+	}, inter)
+
+	resp, err = getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithIgnore(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.WriteHeader(http.StatusOK)
+		ctx.SetError(gerror.NewCode(gcode.CodeNotFound, "ut-not-found"))
+	}, Middleware(WithPathToIgnore("/ut")))
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.NotEqual(t, http.StatusNotFound, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+}
+
+func startServer(t *testing.T, usherHandler ghttp.HandlerFunc, inters ...ghttp.HandlerFunc) *ghttp.Server {
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8091)
+	server.SetDumpRouterMap(false)
+	server.BindMiddlewareDefault(inters...)
+	server.BindHandler("/ut", usherHandler)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	assert.Nil(t, server.Start())
+
+	return server
+}
+
+func getClient() *gclient.Client {
+	time.Sleep(100 * time.Millisecond)
+	client := g.Client()
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://127.0.0.1:8091")
+
+	return client
+}
+
+func assertNotPanic(t *testing.T) {
+	if r := recover(); r != nil {
+		// Expect panic to be called with non nil error
+		assert.True(t, false)
+	} else {
+		// This should never be called in case of a bug
+		assert.True(t, true)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfgcode
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"net/http"
+	"strings"
+)
+
+// defaultMapping maps builtin gcode.Code of GoFrame to HTTP status code
+var defaultMapping = map[int]int{
+	gcode.CodeNil.Code():                      http.StatusInternalServerError,
+	gcode.CodeOK.Code():                       http.StatusOK,
+	gcode.CodeInternalError.Code():            http.StatusInternalServerError,
+	gcode.CodeValidationFailed.Code():         http.StatusBadRequest,
+	gcode.CodeDbOperationError.Code():         http.StatusInternalServerError,
+	gcode.CodeInvalidParameter.Code():         http.StatusBadRequest,
+	gcode.CodeMissingParameter.Code():         http.StatusBadRequest,
+	gcode.CodeInvalidOperation.Code():         http.StatusBadRequest,
+	gcode.CodeInvalidConfiguration.Code():     http.StatusInternalServerError,
+	gcode.CodeMissingConfiguration.Code():     http.StatusInternalServerError,
+	gcode.CodeNotImplemented.Code():           http.StatusNotImplemented,
+	gcode.CodeNotSupported.Code():             http.StatusNotImplemented,
+	gcode.CodeOperationFailed.Code():          http.StatusInternalServerError,
+	gcode.CodeNotAuthorized.Code():            http.StatusUnauthorized,
+	gcode.CodeSecurityReason.Code():           http.StatusForbidden,
+	gcode.CodeServerBusy.Code():               http.StatusServiceUnavailable,
+	gcode.CodeUnknown.Code():                  http.StatusInternalServerError,
+	gcode.CodeNotFound.Code():                 http.StatusNotFound,
+	gcode.CodeInvalidRequest.Code():           http.StatusBadRequest,
+	gcode.CodeInternalPanic.Code():            http.StatusInternalServerError,
+	gcode.CodeBusinessValidationFailed.Code(): http.StatusBadRequest,
+}
+
+// DefaultStatus returns HTTP status code mapped from gcode with default mapping, 500 would be returned if missing.
+func DefaultStatus(code int) int {
+	if status, ok := defaultMapping[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// BootConfig for YAML
+type BootConfig struct {
+	Enabled bool        `yaml:"enabled" json:"enabled"`
+	Ignore  []string    `yaml:"ignore" json:"ignore"`
+	Mapping map[int]int `yaml:"mapping" json:"mapping"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	entryName    string
+	entryType    string
+	mapping      map[int]int
+	pathToIgnore []string
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:    "fake-entry",
+		entryType:    "",
+		mapping:      make(map[int]int),
+		pathToIgnore: []string{},
+	}
+
+	for k, v := range defaultMapping {
+		set.mapping[k] = v
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// Status returns HTTP status code mapped from gcode, 500 would be returned if missing.
+func (set *optionSet) Status(code int) int {
+	if status, ok := set.mapping[code]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ShouldIgnore determine whether path should be ignored
+func (set *optionSet) ShouldIgnore(path string) bool {
+	for i := range set.pathToIgnore {
+		if strings.HasPrefix(path, set.pathToIgnore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(path)
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithPathToIgnore(config.Ignore...))
+
+		for code, status := range config.Mapping {
+			opts = append(opts, WithMapping(code, status))
+		}
+	}
+
+	return opts
+}
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithMapping provide HTTP status code of gcode, default mapping would be overridden.
+func WithMapping(code, status int) Option {
+	return func(opt *optionSet) {
+		opt.mapping[code] = status
+	}
+}
+
+// WithPathToIgnore provide paths prefix that will ignore.
+func WithPathToIgnore(paths ...string) Option {
+	return func(set *optionSet) {
+		for i := range paths {
+			if len(paths[i]) > 0 {
+				set.pathToIgnore = append(set.pathToIgnore, paths[i])
+			}
+		}
+	}
+}
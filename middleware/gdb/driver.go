@@ -0,0 +1,145 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfgdb bridges request context of GoFrame middlewares into gdb ORM with logging, metrics and tracing
+package rkgfgdb
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/rookie-ninja/rk-logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// tableRegex matches table name after keywords of SQL
+var tableRegex = regexp.MustCompile("(?i)\\b(?:from|into|update|join)\\s+([`\"\\[\\]\\w.]+)")
+
+// Register wraps driver with NewDriver() and registers it into gdb with name.
+//
+// Use name of original driver, such as mysql, to override it.
+func Register(name string, driver gdb.Driver, opts ...Option) error {
+	return gdb.Register(name, NewDriver(driver, opts...))
+}
+
+// NewDriver wraps driver, SQL statements executed with request context would be logged with request logger,
+// recorded as child span of request, timed into event and measured in prom histogram labeled by table and operation.
+func NewDriver(driver gdb.Driver, opts ...Option) gdb.Driver {
+	return &Driver{
+		driver: driver,
+		set:    newOptionSet(opts...),
+	}
+}
+
+// Driver wraps gdb.Driver.
+type Driver struct {
+	driver gdb.Driver
+	set    *optionSet
+}
+
+// New creates DB with original driver, and installs logger of gdb.
+func (d *Driver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	db, err := d.driver.New(core, node)
+	if err != nil {
+		return nil, err
+	}
+
+	if core != nil {
+		core.SetLogger(newLogger(d.set.logger))
+	}
+
+	return &DB{
+		DB:  db,
+		set: d.set,
+	}, nil
+}
+
+// DB wraps gdb.DB and intercepts every SQL statement.
+type DB struct {
+	gdb.DB
+	set *optionSet
+}
+
+// DoCommit commits SQL statement with original DB.
+func (db *DB) DoCommit(ctx context.Context, in gdb.DoCommitInput) (out gdb.DoCommitOutput, err error) {
+	operation, table := parseSql(in.Type, in.Sql)
+	name := "gdb." + operation
+
+	newCtx, span := rkgfctx.NewTraceSpanFromCtx(ctx, name)
+	span.SetAttributes(
+		attribute.String("db.operation", operation),
+		attribute.String("db.sql.table", table),
+		attribute.String("db.statement", in.Sql))
+
+	startTime := time.Now()
+	out, err = db.DB.DoCommit(newCtx, in)
+	elapsed := time.Since(startTime)
+
+	event := rkgfctx.GetEventFromCtx(ctx)
+
+	if err != nil {
+		event.AddErr(err)
+		span.RecordError(err)
+	}
+	rkgfctx.EndTraceSpanFromCtx(newCtx, span, err == nil)
+
+	db.set.histogram.WithLabelValues(
+		db.set.GetEntryName(), db.set.GetEntryType(), table, operation).Observe(elapsed.Seconds())
+
+	logger := rkgfctx.GetLoggerFromCtx(ctx)
+	if logger == rklogger.NoopLogger {
+		logger = db.set.logger
+	}
+
+	// args may contain sensitive data, they are logged only if enabled explicitly
+	sql := in.Sql
+	if db.set.sqlArgs {
+		sql = gdb.FormatSqlWithArgs(in.Sql, in.Args)
+	}
+
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.String("table", table),
+		zap.String("operation", operation),
+		zap.Bool("transaction", in.IsTransaction),
+		zap.Duration("elapsed", elapsed),
+	}
+
+	if err != nil {
+		logger.Error("Failed to execute SQL", append(fields, zap.Error(err))...)
+	} else {
+		logger.Debug("Executed SQL", fields...)
+	}
+
+	return out, err
+}
+
+// parseSql returns operation and table of SQL statement, table would be empty for transaction operations.
+func parseSql(sqlType gdb.SqlType, sql string) (operation, table string) {
+	switch sqlType {
+	case gdb.SqlTypeBegin:
+		return "BEGIN", ""
+	case gdb.SqlTypeTXCommit:
+		return "COMMIT", ""
+	case gdb.SqlTypeTXRollback:
+		return "ROLLBACK", ""
+	}
+
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	} else {
+		operation = "UNKNOWN"
+	}
+
+	if match := tableRegex.FindStringSubmatch(sql); len(match) > 1 {
+		table = strings.NewReplacer("`", "", "\"", "", "[", "", "]", "").Replace(match[1])
+	}
+
+	return operation, table
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfgdb
+
+import (
+	"context"
+	"errors"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+type fakeDB struct {
+	gdb.DB
+	err error
+}
+
+func (db *fakeDB) DoCommit(ctx context.Context, in gdb.DoCommitInput) (gdb.DoCommitOutput, error) {
+	return gdb.DoCommitOutput{}, db.err
+}
+
+type fakeDriver struct {
+	err error
+}
+
+func (d *fakeDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &fakeDB{}, d.err
+}
+
+func TestDriver_New(t *testing.T) {
+	// with error
+	driver := NewDriver(&fakeDriver{err: errors.New("ut-error")}, WithRegisterer(prometheus.NewRegistry()))
+	db, err := driver.New(nil, nil)
+	assert.Nil(t, db)
+	assert.NotNil(t, err)
+
+	// without error
+	driver = NewDriver(&fakeDriver{}, WithRegisterer(prometheus.NewRegistry()))
+	db, err = driver.New(nil, nil)
+	assert.Nil(t, err)
+	assert.IsType(t, &DB{}, db)
+}
+
+func TestDB_DoCommit(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	core, logs := observer.New(zap.DebugLevel)
+
+	driver := NewDriver(&fakeDriver{},
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithRegisterer(registry))
+	db, _ := driver.New(nil, nil)
+
+	event := rkquery.NewEventFactory().CreateEvent()
+	event.SetStartTime(time.Now())
+	ctx := context.WithValue(context.TODO(), rkmid.EventKey, event)
+	ctx = context.WithValue(ctx, rkmid.LoggerKey, zap.New(core))
+
+	// query
+	_, err := db.DoCommit(ctx, gdb.DoCommitInput{
+		Sql:  "SELECT * FROM `user` WHERE `id`=?",
+		Args: []interface{}{1},
+		Type: gdb.SqlTypeQueryContext,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gdb_elapsedSecond"))
+	assert.Equal(t, 1, logs.FilterMessage("Executed SQL").FilterField(zap.String("table", "user")).Len())
+	assert.Equal(t, 1, logs.FilterField(zap.String("sql", "SELECT * FROM `user` WHERE `id`=?")).Len())
+	assert.Equal(t, zap.DebugLevel, logs.FilterMessage("Executed SQL").All()[0].Level)
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gdb.SELECT"))
+
+	// exec with error
+	db.(*DB).DB = &fakeDB{err: errors.New("ut-error")}
+	_, err = db.DoCommit(ctx, gdb.DoCommitInput{
+		Sql:  "UPDATE `user` SET `name`=? WHERE `id`=?",
+		Type: gdb.SqlTypeExecContext,
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "rk_gdb_elapsedSecond"))
+	assert.Equal(t, 1, logs.FilterMessage("Failed to execute SQL").Len())
+	assert.Equal(t, int64(1), event.GetErrCount(err))
+
+	// registered twice
+	assert.NotNil(t, NewDriver(&fakeDriver{}, WithRegisterer(registry)))
+}
+
+func TestDB_DoCommit_WithSqlArgs(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+
+	driver := NewDriver(&fakeDriver{},
+		WithLogger(zap.New(core)),
+		WithRegisterer(prometheus.NewRegistry()),
+		WithSqlArgs(true))
+	db, _ := driver.New(nil, nil)
+
+	_, err := db.DoCommit(context.TODO(), gdb.DoCommitInput{
+		Sql:  "SELECT * FROM `user` WHERE `id`=?",
+		Args: []interface{}{1},
+		Type: gdb.SqlTypeQueryContext,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, logs.FilterField(zap.String("sql", "SELECT * FROM `user` WHERE `id`=1")).Len())
+}
+
+func TestParseSql(t *testing.T) {
+	cases := []struct {
+		sqlType               gdb.SqlType
+		sql, operation, table string
+	}{
+		{gdb.SqlTypeQueryContext, "SELECT * FROM `user` WHERE id=1", "SELECT", "user"},
+		{gdb.SqlTypeQueryContext, "select count(1) from (select * from db.order) t", "SELECT", "db.order"},
+		{gdb.SqlTypeExecContext, "INSERT INTO `user`(`name`) VALUES(?)", "INSERT", "user"},
+		{gdb.SqlTypeExecContext, "UPDATE \"user\" SET name=?", "UPDATE", "user"},
+		{gdb.SqlTypeExecContext, "DELETE FROM [user] WHERE id=?", "DELETE", "user"},
+		{gdb.SqlTypeExecContext, "SHOW TABLES", "SHOW", ""},
+		{gdb.SqlTypeExecContext, "", "UNKNOWN", ""},
+		{gdb.SqlTypeBegin, "", "BEGIN", ""},
+		{gdb.SqlTypeTXCommit, "", "COMMIT", ""},
+		{gdb.SqlTypeTXRollback, "", "ROLLBACK", ""},
+	}
+
+	for _, c := range cases {
+		operation, table := parseSql(c.sqlType, c.sql)
+		assert.Equal(t, c.operation, operation, c.sql)
+		assert.Equal(t, c.table, table, c.sql)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	fallbackCore, fallbackLogs := observer.New(zap.DebugLevel)
+	requestCore, requestLogs := observer.New(zap.DebugLevel)
+	l := newLogger(zap.New(fallbackCore))
+
+	// without request logger
+	l.Debugf(context.TODO(), "ut-%s", "debug")
+	l.Fatal(context.TODO(), "ut-fatal")
+	assert.Equal(t, 2, fallbackLogs.Len())
+	assert.Equal(t, 1, fallbackLogs.FilterMessage("ut-debug").Len())
+
+	// with request logger
+	ctx := context.WithValue(context.TODO(), rkmid.LoggerKey, zap.New(requestCore))
+	l.Warning(ctx, "ut-warning")
+	assert.Equal(t, 2, fallbackLogs.Len())
+	assert.Equal(t, 1, requestLogs.FilterMessage("ut-warning").Len())
+}
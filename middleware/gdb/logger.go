@@ -0,0 +1,112 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfgdb
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogf/gf/v2/os/glog"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/rookie-ninja/rk-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logger implements glog.ILogger of gdb with request logger in context, fallback logger would be used if missing.
+type logger struct {
+	fallback *zap.Logger
+}
+
+func newLogger(fallback *zap.Logger) glog.ILogger {
+	return &logger{fallback: fallback}
+}
+
+func (l *logger) log(ctx context.Context, level zapcore.Level, msg string) {
+	res := rkgfctx.GetLoggerFromCtx(ctx)
+	if res == rklogger.NoopLogger {
+		res = l.fallback
+	}
+
+	if ce := res.WithOptions(zap.AddCallerSkip(2)).Check(level, msg); ce != nil {
+		ce.Write()
+	}
+}
+
+func (l *logger) Print(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Printf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Debug(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.DebugLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Debugf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.DebugLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Info(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Infof(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Notice(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Noticef(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Warning(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.WarnLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Warningf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.WarnLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Error(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Errorf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(format, v...))
+}
+
+func (l *logger) Critical(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprint(v...))
+}
+
+func (l *logger) Criticalf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(format, v...))
+}
+
+// Panic logs in error level without panic, since gdb should not crash process by logging.
+func (l *logger) Panic(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprint(v...))
+}
+
+// Panicf logs in error level without panic, since gdb should not crash process by logging.
+func (l *logger) Panicf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(format, v...))
+}
+
+// Fatal logs in error level without exit, since gdb should not crash process by logging.
+func (l *logger) Fatal(ctx context.Context, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprint(v...))
+}
+
+// Fatalf logs in error level without exit, since gdb should not crash process by logging.
+func (l *logger) Fatalf(ctx context.Context, format string, v ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfgdb
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// MetricsNameElapsedSecond records duration of SQL statement
+	MetricsNameElapsedSecond = "elapsedSecond"
+)
+
+// labelKeys are labels of prometheus metrics
+var labelKeys = []string{
+	"entryName",
+	"entryType",
+	"table",
+	"operation",
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for driver implementation
+type optionSet struct {
+	entryName  string
+	entryType  string
+	logger     *zap.Logger
+	registerer prometheus.Registerer
+	histogram  *prometheus.HistogramVec
+	sqlArgs    bool
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:  "fake-entry",
+		entryType:  "",
+		logger:     rklogger.NoopLogger,
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	set.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rk",
+		Subsystem: "gdb",
+		Name:      MetricsNameElapsedSecond,
+		Help:      "Histogram of SQL statements executed by gdb",
+		Buckets:   prometheus.DefBuckets,
+	}, labelKeys)
+
+	// reuse histogram registered by another driver
+	if err := set.registerer.Register(set.histogram); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(*prometheus.HistogramVec); ok {
+				set.histogram = existing
+			}
+		}
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// ***************** Option *****************
+
+// Option if for driver options while creating driver
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithLogger provide zap logger used if request logger is missing in context.
+func WithLogger(logger *zap.Logger) Option {
+	return func(opt *optionSet) {
+		if logger != nil {
+			opt.logger = logger
+		}
+	}
+}
+
+// WithRegisterer provide prometheus.Registerer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(opt *optionSet) {
+		if registerer != nil {
+			opt.registerer = registerer
+		}
+	}
+}
+
+// WithSqlArgs enable logging of SQL statement with args, statement template without args would be logged by default.
+//
+// Be careful, args may contain sensitive data like password and token.
+func WithSqlArgs(enabled bool) Option {
+	return func(opt *optionSet) {
+		opt.sqlArgs = enabled
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfredis instruments commands of GoFrame gredis client with tracing, event timers and prom metrics
+package rkgfredis
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"go.opentelemetry.io/otel/attribute"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RegisterAdapterFunc wraps adapters created by adapterFunc with NewAdapter(), and registers it into gredis,
+// so that clients created by gredis.New() and g.Redis() are instrumented.
+func RegisterAdapterFunc(adapterFunc gredis.AdapterFunc, opts ...Option) {
+	set := newOptionSet(opts...)
+
+	gredis.RegisterAdapterFunc(func(config *gredis.Config) gredis.Adapter {
+		adapter := adapterFunc(config)
+		if adapter == nil {
+			return nil
+		}
+
+		return newAdapter(adapter, set)
+	})
+}
+
+// NewAdapter wraps Do() and Conn() of adapter, commands would be recorded as child span of request,
+// timed into event and measured in prom histogram and counter labeled by command.
+//
+// Adapters like redis.Redis of GoFrame contrib execute commands of groups with embedded gredis.AdapterOperation,
+// it is replaced with wrapped operation, so that commands of groups like g.Redis().Set() are instrumented as well.
+func NewAdapter(adapter gredis.Adapter, opts ...Option) gredis.Adapter {
+	return newAdapter(adapter, newOptionSet(opts...))
+}
+
+func newAdapter(adapter gredis.Adapter, set *optionSet) *Adapter {
+	field := reflect.ValueOf(adapter)
+	if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
+		field = field.Elem().FieldByName("AdapterOperation")
+	}
+
+	// groups of adapter delegate to embedded operation
+	if field.IsValid() && field.CanSet() && field.Type() == operationType && !field.IsNil() {
+		operation := &Operation{AdapterOperation: field.Interface().(gredis.AdapterOperation), set: set}
+		field.Set(reflect.ValueOf(operation))
+		return &Adapter{Adapter: adapter, operation: operation}
+	}
+
+	return &Adapter{Adapter: adapter, operation: &Operation{AdapterOperation: adapter, set: set}}
+}
+
+var operationType = reflect.TypeOf((*gredis.AdapterOperation)(nil)).Elem()
+
+// Adapter wraps gredis.Adapter, groups are provided by original adapter.
+type Adapter struct {
+	gredis.Adapter
+	operation *Operation
+}
+
+// Do sends command with wrapped operation.
+func (a *Adapter) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return a.operation.Do(ctx, command, args...)
+}
+
+// Conn retrieves connection with wrapped operation.
+func (a *Adapter) Conn(ctx context.Context) (gredis.Conn, error) {
+	return a.operation.Conn(ctx)
+}
+
+// NewAdapterOperation wraps Do() and Conn() of operation, it could be assigned to adapters which execute commands of
+// groups with embedded gredis.AdapterOperation.
+func NewAdapterOperation(operation gredis.AdapterOperation, opts ...Option) gredis.AdapterOperation {
+	return &Operation{
+		AdapterOperation: operation,
+		set:              newOptionSet(opts...),
+	}
+}
+
+// Operation wraps gredis.AdapterOperation.
+type Operation struct {
+	gredis.AdapterOperation
+	set *optionSet
+}
+
+// Do sends command with original operation.
+func (o *Operation) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return o.set.do(ctx, command, func(ctx context.Context) (*gvar.Var, error) {
+		return o.AdapterOperation.Do(ctx, command, args...)
+	})
+}
+
+// Conn retrieves connection of original operation.
+func (o *Operation) Conn(ctx context.Context) (gredis.Conn, error) {
+	conn, err := o.AdapterOperation.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{Conn: conn, set: o.set}, nil
+}
+
+// Conn wraps gredis.Conn.
+type Conn struct {
+	gredis.Conn
+	set *optionSet
+}
+
+// Do sends command with original connection.
+func (c *Conn) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return c.set.do(ctx, command, func(ctx context.Context) (*gvar.Var, error) {
+		return c.Conn.Do(ctx, command, args...)
+	})
+}
+
+// Subscribe subscribes channels with original connection.
+func (c *Conn) Subscribe(ctx context.Context, channel string, channels ...string) (subs []*gredis.Subscription, err error) {
+	_, err = c.set.do(ctx, "SUBSCRIBE", func(ctx context.Context) (*gvar.Var, error) {
+		subs, err = c.Conn.Subscribe(ctx, channel, channels...)
+		return nil, err
+	})
+
+	return subs, err
+}
+
+// PSubscribe subscribes patterns with original connection.
+func (c *Conn) PSubscribe(ctx context.Context, pattern string, patterns ...string) (subs []*gredis.Subscription, err error) {
+	_, err = c.set.do(ctx, "PSUBSCRIBE", func(ctx context.Context) (*gvar.Var, error) {
+		subs, err = c.Conn.PSubscribe(ctx, pattern, patterns...)
+		return nil, err
+	})
+
+	return subs, err
+}
+
+// do executes command with span, event timer and metrics.
+func (set *optionSet) do(ctx context.Context, command string, f func(ctx context.Context) (*gvar.Var, error)) (*gvar.Var, error) {
+	command = strings.ToUpper(command)
+	name := "gredis." + command
+
+	newCtx, span := rkgfctx.NewTraceSpanFromCtx(ctx, name)
+	span.SetAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", command))
+
+	startTime := time.Now()
+	res, err := f(newCtx)
+	elapsed := time.Since(startTime)
+
+	event := rkgfctx.GetEventFromCtx(ctx)
+
+	values := []string{set.GetEntryName(), set.GetEntryType(), command}
+	set.histogram.WithLabelValues(values...).Observe(elapsed.Seconds())
+
+	if err != nil {
+		event.AddErr(err)
+		span.RecordError(err)
+		set.counter.WithLabelValues(values...).Inc()
+	}
+	rkgfctx.EndTraceSpanFromCtx(newCtx, span, err == nil)
+
+	return res, err
+}
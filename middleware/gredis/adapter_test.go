@@ -0,0 +1,248 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfredis
+
+import (
+	"context"
+	"errors"
+	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAdapter is an in-process redis stand-in which supports GET, SET and SUBSCRIBE only,
+// commands of groups are executed with embedded gredis.AdapterOperation like redis.Redis of GoFrame contrib.
+type fakeAdapter struct {
+	gredis.AdapterGroup
+	gredis.AdapterOperation
+	lock  sync.Mutex
+	store map[string]interface{}
+}
+
+func newFakeAdapter() *fakeAdapter {
+	adapter := &fakeAdapter{store: map[string]interface{}{}}
+	adapter.AdapterOperation = adapter
+	return adapter
+}
+
+func (a *fakeAdapter) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	switch strings.ToUpper(command) {
+	case "GET":
+		return gvar.New(a.store[gvar.New(args[0]).String()]), nil
+	case "SET":
+		a.store[gvar.New(args[0]).String()] = args[1]
+		return gvar.New("OK"), nil
+	default:
+		return nil, errors.New("ERR unknown command")
+	}
+}
+
+func (a *fakeAdapter) Conn(ctx context.Context) (gredis.Conn, error) {
+	return &fakeConn{adapter: a}, nil
+}
+
+func (a *fakeAdapter) Close(ctx context.Context) error {
+	return nil
+}
+
+func (a *fakeAdapter) GroupString() gredis.IGroupString {
+	return fakeGroupString{operation: a.AdapterOperation}
+}
+
+func (a *fakeAdapter) GroupPubSub() gredis.IGroupPubSub {
+	return fakeGroupPubSub{operation: a.AdapterOperation}
+}
+
+func (a *fakeAdapter) GroupGeneric() gredis.IGroupGeneric     { return nil }
+func (a *fakeAdapter) GroupHash() gredis.IGroupHash           { return nil }
+func (a *fakeAdapter) GroupList() gredis.IGroupList           { return nil }
+func (a *fakeAdapter) GroupScript() gredis.IGroupScript       { return nil }
+func (a *fakeAdapter) GroupSet() gredis.IGroupSet             { return nil }
+func (a *fakeAdapter) GroupSortedSet() gredis.IGroupSortedSet { return nil }
+
+type fakeGroupString struct {
+	gredis.IGroupString
+	operation gredis.AdapterOperation
+}
+
+func (g fakeGroupString) Set(ctx context.Context, key string, value interface{}, option ...gredis.SetOption) (*gvar.Var, error) {
+	return g.operation.Do(ctx, "Set", key, value)
+}
+
+func (g fakeGroupString) Get(ctx context.Context, key string) (*gvar.Var, error) {
+	return g.operation.Do(ctx, "Get", key)
+}
+
+type fakeGroupPubSub struct {
+	gredis.IGroupPubSub
+	operation gredis.AdapterOperation
+}
+
+func (g fakeGroupPubSub) Subscribe(ctx context.Context, channel string, channels ...string) (gredis.Conn, []*gredis.Subscription, error) {
+	conn, err := g.operation.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subs, err := conn.Subscribe(ctx, channel, channels...)
+	return conn, subs, err
+}
+
+type fakeConn struct {
+	gredis.Conn
+	adapter *fakeAdapter
+}
+
+func (c *fakeConn) Do(ctx context.Context, command string, args ...interface{}) (*gvar.Var, error) {
+	return c.adapter.Do(ctx, command, args...)
+}
+
+func (c *fakeConn) Subscribe(ctx context.Context, channel string, channels ...string) ([]*gredis.Subscription, error) {
+	subs := make([]*gredis.Subscription, 0)
+	for i, v := range append([]string{channel}, channels...) {
+		subs = append(subs, &gredis.Subscription{Kind: "subscribe", Channel: v, Count: i + 1})
+	}
+
+	return subs, nil
+}
+
+func (c *fakeConn) Close(ctx context.Context) error {
+	return nil
+}
+
+func newCtx() (context.Context, rkquery.Event) {
+	event := rkquery.NewEventFactory().CreateEvent()
+	event.SetStartTime(time.Now())
+	return context.WithValue(context.TODO(), rkmid.EventKey, event), event
+}
+
+func TestAdapter_Do(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	adapter := NewAdapter(newFakeAdapter(),
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithRegisterer(registry))
+	redis, err := gredis.NewWithAdapter(adapter)
+	assert.Nil(t, err)
+
+	ctx, event := newCtx()
+
+	// success
+	_, err = redis.Do(ctx, "set", "key", "value")
+	assert.Nil(t, err)
+	res, err := redis.Do(ctx, "get", "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", res.String())
+
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.SET"))
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.GET"))
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "rk_gredis_elapsedSecond"))
+	assert.Equal(t, 0, testutil.CollectAndCount(registry, "rk_gredis_error"))
+
+	// with error
+	_, err = redis.Do(ctx, "hgetall", "key")
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(1), event.GetErrCount(err))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gredis_error"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(
+		adapter.(*Adapter).operation.set.counter.WithLabelValues("ut-entry", "ut-type", "HGETALL")))
+}
+
+func TestAdapter_Group(t *testing.T) {
+	defer gredis.RegisterAdapterFunc(nil)
+
+	registry := prometheus.NewRegistry()
+	RegisterAdapterFunc(func(config *gredis.Config) gredis.Adapter {
+		return newFakeAdapter()
+	}, WithEntryNameAndType("ut-entry", "ut-type"), WithRegisterer(registry))
+	gredis.SetConfig(&gredis.Config{Address: "localhost:6379"}, "ut-group")
+	defer gredis.RemoveConfig("ut-group")
+
+	ctx, event := newCtx()
+
+	// groups of adapter delegate to wrapped operation
+	_, err := g.Redis("ut-group").Set(ctx, "key", "value")
+	assert.Nil(t, err)
+	res, err := g.Redis("ut-group").Get(ctx, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", res.String())
+
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.SET"))
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.GET"))
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "rk_gredis_elapsedSecond"))
+
+	// subscribe with connection of wrapped operation
+	conn, subs, err := g.Redis("ut-group").Subscribe(ctx, "channel")
+	assert.Nil(t, err)
+	assert.IsType(t, &Conn{}, conn)
+	assert.Len(t, subs, 1)
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.SUBSCRIBE"))
+	assert.Equal(t, 3, testutil.CollectAndCount(registry, "rk_gredis_elapsedSecond"))
+}
+
+func TestNewAdapter_WithoutOperationField(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	redis, err := gredis.NewWithAdapter(NewAdapter(&struct{ gredis.Adapter }{newFakeAdapter()}, WithRegisterer(registry)))
+	assert.Nil(t, err)
+
+	ctx, event := newCtx()
+
+	// Do() of adapter is instrumented
+	_, err = redis.Do(ctx, "set", "key", "value")
+	assert.Nil(t, err)
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.SET"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gredis_elapsedSecond"))
+}
+
+func TestAdapter_Conn(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	redis, err := gredis.NewWithAdapter(NewAdapter(newFakeAdapter(), WithRegisterer(registry)))
+	assert.Nil(t, err)
+
+	ctx, event := newCtx()
+
+	conn, err := redis.Conn(ctx)
+	assert.Nil(t, err)
+	assert.IsType(t, &Conn{}, conn)
+
+	_, err = conn.Do(ctx, "set", "key", "value")
+	assert.Nil(t, err)
+	assert.NotEqual(t, int64(-1), event.GetTimeElapsedMs("gredis.SET"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_gredis_elapsedSecond"))
+}
+
+func TestNewAdapter_WithSameRegisterer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	first := NewAdapter(newFakeAdapter(), WithRegisterer(registry)).(*Adapter)
+	second := NewAdapter(newFakeAdapter(), WithRegisterer(registry)).(*Adapter)
+
+	assert.Equal(t, first.operation.set.histogram, second.operation.set.histogram)
+	assert.Equal(t, first.operation.set.counter, second.operation.set.counter)
+}
+
+func TestRegisterAdapterFunc(t *testing.T) {
+	defer gredis.RegisterAdapterFunc(nil)
+
+	RegisterAdapterFunc(func(config *gredis.Config) gredis.Adapter {
+		return newFakeAdapter()
+	}, WithRegisterer(prometheus.NewRegistry()))
+
+	redis, err := gredis.New(&gredis.Config{Address: "localhost:6379"})
+	assert.Nil(t, err)
+	assert.IsType(t, &Adapter{}, redis.GetAdapter())
+}
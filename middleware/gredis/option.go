@@ -0,0 +1,120 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfredis
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsNameElapsedSecond records duration of redis command
+	MetricsNameElapsedSecond = "elapsedSecond"
+	// MetricsNameError records errors of redis command
+	MetricsNameError = "error"
+)
+
+// labelKeys are labels of prometheus metrics
+var labelKeys = []string{
+	"entryName",
+	"entryType",
+	"command",
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for adapter implementation
+type optionSet struct {
+	entryName  string
+	entryType  string
+	registerer prometheus.Registerer
+	histogram  *prometheus.HistogramVec
+	counter    *prometheus.CounterVec
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:  "fake-entry",
+		entryType:  "",
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	set.histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rk",
+		Subsystem: "gredis",
+		Name:      MetricsNameElapsedSecond,
+		Help:      "Histogram of redis commands executed by gredis",
+		Buckets:   prometheus.DefBuckets,
+	}, labelKeys)
+	if existing := register(set.registerer, set.histogram); existing != nil {
+		if v, ok := existing.(*prometheus.HistogramVec); ok {
+			set.histogram = v
+		}
+	}
+
+	set.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rk",
+		Subsystem: "gredis",
+		Name:      MetricsNameError,
+		Help:      "Counter of failed redis commands executed by gredis",
+	}, labelKeys)
+	if existing := register(set.registerer, set.counter); existing != nil {
+		if v, ok := existing.(*prometheus.CounterVec); ok {
+			set.counter = v
+		}
+	}
+
+	return set
+}
+
+// register registers collector, existing collector would be returned if registered by another adapter.
+func register(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	if err := registerer.Register(collector); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			return are.ExistingCollector
+		}
+	}
+
+	return nil
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// ***************** Option *****************
+
+// Option if for adapter options while creating adapter
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithRegisterer provide prometheus.Registerer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(opt *optionSet) {
+		if registerer != nil {
+			opt.registerer = registerer
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfhandler converts typed handler into ghttp.HandlerFunc with binding, validation and error model of entry
+package rkgfhandler
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/util/gvalid"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/rookie-ninja/rk-gf/middleware/gcode"
+	"github.com/rookie-ninja/rk-gf/middleware/validation"
+	"net/http"
+)
+
+// Handler converts typed handler into ghttp.HandlerFunc.
+//
+// Request is bound from path, query and body by ghttp.Request.Parse() and validated with gvalid rules in struct tags.
+// Failures of binding and validation are recorded in event as counters of bindError and validationError,
+// and written with error model of entry, as well as errors returned by handler.
+// Validation failures are written with field violations as details, see rkgfvalid.NewError() for details.
+//
+// Response would be wrapped in envelope if response middleware enabled, otherwise written as JSON.
+func Handler[Req any, Res any](fn func(ctx context.Context, req *Req) (*Res, error)) ghttp.HandlerFunc {
+	return func(ctx *ghttp.Request) {
+		req := new(Req)
+
+		if err := ctx.Parse(req); err != nil {
+			writeParseError(ctx, err)
+			return
+		}
+
+		res, err := fn(ctx.Context(), req)
+		if err != nil {
+			writeHandlerError(ctx, err)
+			return
+		}
+
+		if rkgfctx.GetEnvelope(ctx) != nil {
+			rkgfctx.WriteEnvelope(ctx, http.StatusOK, "", res)
+			return
+		}
+
+		if res != nil {
+			ctx.Response.WriteJson(res)
+		}
+	}
+}
+
+// writeParseError records binding or validation failure in event and writes error response with status of 400.
+func writeParseError(ctx *ghttp.Request, err error) {
+	event := rkgfctx.GetEvent(ctx)
+	event.AddErr(err)
+
+	if vErr, ok := err.(gvalid.Error); ok {
+		event.IncCounter("validationError", 1)
+		rkgfctx.WriteError(ctx, rkgfvalid.NewError(ctx, vErr))
+		return
+	}
+
+	event.IncCounter("bindError", 1)
+	rkgfctx.WriteError(ctx, rkgfctx.GetErrorBuilder(ctx).New(http.StatusBadRequest, "Failed to bind request", err))
+}
+
+// writeHandlerError writes error returned by handler.
+//
+// Error of rk error model is written as it is. Other errors are set into request and written by gcode middleware
+// with mapping of entry if it is enabled, otherwise they are written with HTTP status mapped from default mapping of gcode.
+func writeHandlerError(ctx *ghttp.Request, err error) {
+	if rkErr, ok := err.(rkerror.ErrorInterface); ok {
+		rkgfctx.GetEvent(ctx).AddErr(err)
+		rkgfctx.WriteError(ctx, rkErr)
+		return
+	}
+
+	ctx.SetError(err)
+	if ctx.GetCtxVar(rkgfinter.GcodeEnabledKey).Bool() {
+		return
+	}
+
+	rkgfctx.GetEvent(ctx).AddErr(err)
+	status := rkgfgcode.DefaultStatus(gerror.Code(err).Code())
+	rkgfctx.WriteError(ctx, rkgfctx.GetErrorBuilder(ctx).New(status, err.Error()))
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfhandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/gcode"
+	"github.com/rookie-ninja/rk-query"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type utHandlerReq struct {
+	Id   int    `p:"id" v:"required|min:1"`
+	Name string `p:"name" v:"required"`
+}
+
+type utHandlerRes struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var utHandler = Handler(func(ctx context.Context, req *utHandlerReq) (*utHandlerRes, error) {
+	switch req.Name {
+	case "ut-not-found":
+		return nil, gerror.NewCode(gcode.CodeNotFound, "ut-not-found")
+	case "ut-error":
+		return nil, errors.New("ut-error")
+	}
+
+	return &utHandlerRes{Id: req.Id, Name: req.Name}, nil
+})
+
+func TestHandler(t *testing.T) {
+	defer assertNotPanic(t)
+
+	event := rkquery.NewEventFactory().CreateEvent()
+	withEvent := func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.EventKey, event)
+		ctx.Middleware.Next()
+	}
+	server := startServer(t, withEvent)
+
+	// bind path and body
+	code, m := post(t, "/ut/1", `{"name":"ut-name"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), m["id"])
+	assert.Equal(t, "ut-name", m["name"])
+
+	// validation failure
+	code, m = post(t, "/ut/0", `{"name":"ut-name"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.NotNil(t, m["error"])
+	assert.Equal(t, int64(1), event.GetCounter("validationError"))
+
+	// bind failure
+	code, _ = post(t, "/ut-batch", `[{"name":`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, int64(1), event.GetCounter("bindError"))
+
+	// handler errors
+	code, _ = post(t, "/ut/1", `{"name":"ut-not-found"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = post(t, "/ut/1", `{"name":"ut-error"}`)
+	assert.Equal(t, http.StatusInternalServerError, code)
+
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestHandler_WithGcodeMiddleware(t *testing.T) {
+	defer assertNotPanic(t)
+
+	// gcode read by prom middleware which is placed before gcode middleware
+	gcodeCh := make(chan interface{}, 1)
+	readGcode := func(ctx *ghttp.Request) {
+		ctx.Middleware.Next()
+		gcodeCh <- ctx.GetCtxVar(rkgfinter.GcodeKey).Interface()
+	}
+
+	server := startServer(t, readGcode, rkgfgcode.Middleware(rkgfgcode.WithMapping(gcode.CodeNotFound.Code(), http.StatusGone)))
+
+	// error is written by gcode middleware with mapping of entry
+	resp, err := getClient().Post(context.TODO(), "/ut/1", `{"name":"ut-not-found"}`)
+	assert.Nil(t, err)
+	defer resp.Close()
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(resp.ReadAll(), &m))
+	assert.NotNil(t, m["error"])
+	assert.Equal(t, gcode.CodeNotFound.Code(), <-gcodeCh)
+
+	// validation error of rk error model is written by handler
+	code, _ := post(t, "/ut/0", `{"name":"ut-name"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Nil(t, <-gcodeCh)
+
+	assert.Nil(t, server.Shutdown())
+}
+
+func post(t *testing.T, urlPath, body string) (int, map[string]interface{}) {
+	resp, err := getClient().Post(context.TODO(), urlPath, body)
+	assert.Nil(t, err)
+	if resp == nil {
+		return 0, nil
+	}
+	defer resp.Close()
+
+	m := map[string]interface{}{}
+	json.Unmarshal(resp.ReadAll(), &m)
+	return resp.StatusCode, m
+}
+
+func startServer(t *testing.T, inters ...ghttp.HandlerFunc) *ghttp.Server {
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8097)
+	server.SetDumpRouterMap(false)
+	server.BindMiddlewareDefault(inters...)
+	server.BindHandler("/ut/{id}", utHandler)
+	server.BindHandler("/ut-batch", Handler(func(ctx context.Context, req *[]utHandlerRes) (*[]utHandlerRes, error) {
+		return req, nil
+	}))
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	assert.Nil(t, server.Start())
+
+	return server
+}
+
+func getClient() *gclient.Client {
+	time.Sleep(100 * time.Millisecond)
+	client := g.Client()
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://127.0.0.1:8097")
+	client.SetHeader("Content-Type", "application/json")
+
+	return client
+}
+
+func assertNotPanic(t *testing.T) {
+	if r := recover(); r != nil {
+		// Expect panic to be called with non nil error
+		assert.True(t, false)
+	} else {
+		// This should never be called in case of a bug
+		assert.True(t, true)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gogf/gf/v2/net/ghttp"
 	rkmid "github.com/rookie-ninja/rk-entry/v2/middleware"
 	rkmidjwt "github.com/rookie-ninja/rk-entry/v2/middleware/jwt"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
 )
 
 // Middleware Add CORS interceptors.
@@ -23,7 +24,7 @@ func Middleware(opts ...rkmidjwt.Option) ghttp.HandlerFunc {
 
 		// case 1: error response
 		if beforeCtx.Output.ErrResp != nil {
-			ctx.Response.WriteStatus(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkgfctx.WriteError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
@@ -0,0 +1,74 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfotelmetrics is a middleware for GoFrame framework which records HTTP server metrics
+// following OpenTelemetry semantic conventions
+package rkgfotelmetrics
+
+import (
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"strconv"
+	"time"
+)
+
+// Middleware create a new OpenTelemetry metrics interceptor with options.
+func Middleware(opts ...Option) ghttp.HandlerFunc {
+	set := newOptionSet(opts...)
+
+	return func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.EntryNameKey, set.GetEntryName())
+
+		if set.ShouldIgnore(ctx.URL.Path) {
+			ctx.Middleware.Next()
+			return
+		}
+
+		scheme := "http"
+		if ctx.TLS != nil {
+			scheme = "https"
+		}
+
+		attrs := []attribute.KeyValue{
+			attribute.String(EntryNameKey, set.GetEntryName()),
+			semconv.HTTPRequestMethodKey.String(ctx.Method),
+			semconv.URLScheme(scheme),
+		}
+
+		// route is omitted if no route matched, in order to bound cardinality
+		if r := route(ctx); len(r) > 0 {
+			attrs = append(attrs, semconv.HTTPRoute(r))
+		}
+
+		reqCtx := ctx.Context()
+		activeAttrs := metric.WithAttributes(attrs...)
+		set.activeRequests.Add(reqCtx, 1, activeAttrs)
+		defer set.activeRequests.Add(reqCtx, -1, activeAttrs)
+
+		startTime := time.Now()
+		ctx.Middleware.Next()
+		elapsed := time.Since(startTime)
+
+		attrs = append(attrs, semconv.HTTPResponseStatusCode(ctx.Response.Status))
+		measureAttrs := metric.WithAttributes(attrs...)
+
+		set.duration.Record(reqCtx, elapsed.Seconds(), measureAttrs)
+
+		if ctx.Request.ContentLength >= 0 {
+			set.requestSize.Record(reqCtx, ctx.Request.ContentLength, measureAttrs)
+		}
+
+		resSize := int64(ctx.Response.BufferLength())
+		if resSize < 1 {
+			if v, err := strconv.ParseInt(ctx.Response.Header().Get("Content-Length"), 10, 64); err == nil {
+				resSize = v
+			}
+		}
+		set.responseSize.Record(reqCtx, resSize, measureAttrs)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfotelmetrics
+
+import (
+	"context"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestToOptions(t *testing.T) {
+	config := &BootConfig{Enabled: false}
+	assert.Empty(t, ToOptions(config, "ut-entry", "ut-type", nil))
+
+	config.Enabled = true
+	config.Ignore = []string{"/ut-ignore"}
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type", nil)...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+	assert.False(t, set.ShouldIgnore("/ut"))
+	assert.NotNil(t, set.meterProvider)
+
+	provider := sdkmetric.NewMeterProvider()
+	set = newOptionSet(ToOptions(config, "ut-entry", "ut-type", provider)...)
+	assert.Equal(t, provider, set.meterProvider)
+}
+
+func TestMiddleware(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.TODO())
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-response")
+	}, Middleware(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithPathToIgnore("/ut-ignore"),
+		WithMeterProvider(provider)))
+	server.BindHandler("/ut-users/{id}", func(ctx *ghttp.Request) {})
+	server.BindHandler("/ut-ignore", func(ctx *ghttp.Request) {})
+
+	client := getClient()
+
+	// matched route
+	resp, err := client.Post(context.TODO(), "/ut", "ut-body")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	metrics := collect(t, reader)
+	durations := metrics[MetricsNameDuration].(metricdata.Histogram[float64]).DataPoints
+	assert.Len(t, durations, 1)
+	assert.Equal(t, uint64(1), durations[0].Count)
+	assertAttr(t, durations[0].Attributes, semconv.HTTPRouteKey, "/ut")
+	assertAttr(t, durations[0].Attributes, semconv.HTTPRequestMethodKey, http.MethodPost)
+	assertAttr(t, durations[0].Attributes, semconv.URLSchemeKey, "http")
+	assertAttr(t, durations[0].Attributes, EntryNameKey, "ut-entry")
+	status, _ := durations[0].Attributes.Value(semconv.HTTPResponseStatusCodeKey)
+	assert.Equal(t, int64(http.StatusOK), status.AsInt64())
+
+	assert.Equal(t, int64(len("ut-body")),
+		metrics[MetricsNameRequestSize].(metricdata.Histogram[int64]).DataPoints[0].Sum)
+	assert.Equal(t, int64(len("ut-response")),
+		metrics[MetricsNameResponseSize].(metricdata.Histogram[int64]).DataPoints[0].Sum)
+
+	active := metrics[MetricsNameActiveRequests].(metricdata.Sum[int64]).DataPoints
+	assert.Len(t, active, 1)
+	assert.Equal(t, int64(0), active[0].Value)
+
+	// route pattern instead of raw path
+	resp, err = client.Get(context.TODO(), "/ut-users/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, findDataPoint(collect(t, reader), semconv.HTTPRouteKey.String("/ut-users/{id}")))
+
+	// route is omitted if no route matched
+	resp, err = client.Get(context.TODO(), "/ut-missing")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	point := findDataPoint(collect(t, reader), semconv.HTTPResponseStatusCodeKey.Int(http.StatusNotFound))
+	assert.NotNil(t, point)
+	_, ok := point.Attributes.Value(semconv.HTTPRouteKey)
+	assert.False(t, ok)
+
+	// ignored
+	resp, err = client.Get(context.TODO(), "/ut-ignore")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Len(t, collect(t, reader)[MetricsNameDuration].(metricdata.Histogram[float64]).DataPoints, 3)
+
+	assert.Nil(t, server.Shutdown())
+}
+
+// collect returns aggregations of metrics by name.
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	rm := metricdata.ResourceMetrics{}
+	assert.Nil(t, reader.Collect(context.TODO(), &rm))
+
+	res := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			res[m.Name] = m.Data
+		}
+	}
+
+	return res
+}
+
+// findDataPoint returns data point of duration with attribute.
+func findDataPoint(metrics map[string]metricdata.Aggregation, kv attribute.KeyValue) *metricdata.HistogramDataPoint[float64] {
+	points := metrics[MetricsNameDuration].(metricdata.Histogram[float64]).DataPoints
+	for i := range points {
+		if v, ok := points[i].Attributes.Value(kv.Key); ok && v == kv.Value {
+			return &points[i]
+		}
+	}
+
+	return nil
+}
+
+func assertAttr(t *testing.T, attrs attribute.Set, key attribute.Key, expected string) {
+	value, ok := attrs.Value(key)
+	assert.True(t, ok)
+	assert.Equal(t, expected, value.AsString())
+}
+
+func startServer(t *testing.T, usherHandler ghttp.HandlerFunc, inters ...ghttp.HandlerFunc) *ghttp.Server {
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8096)
+	server.SetDumpRouterMap(false)
+	server.BindMiddlewareDefault(inters...)
+	server.BindHandler("/ut", usherHandler)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	assert.Nil(t, server.Start())
+
+	return server
+}
+
+func getClient() *gclient.Client {
+	time.Sleep(100 * time.Millisecond)
+	client := g.Client()
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://127.0.0.1:8096")
+
+	return client
+}
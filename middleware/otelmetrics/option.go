@@ -0,0 +1,190 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfotelmetrics
+
+import (
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"strings"
+)
+
+const (
+	// InstrumentationName is name of meter
+	InstrumentationName = "github.com/rookie-ninja/rk-gf/middleware/otelmetrics"
+	// MetricsNameDuration records duration of request
+	MetricsNameDuration = "http.server.duration"
+	// MetricsNameActiveRequests records requests being served
+	MetricsNameActiveRequests = "http.server.active_requests"
+	// MetricsNameRequestSize records size of request body
+	MetricsNameRequestSize = "http.server.request.size"
+	// MetricsNameResponseSize records size of response body
+	MetricsNameResponseSize = "http.server.response.size"
+	// EntryNameKey is attribute key of entry name
+	EntryNameKey = "rk.entry.name"
+)
+
+// BootConfig for YAML
+type BootConfig struct {
+	Enabled  bool     `yaml:"enabled" json:"enabled"`
+	Ignore   []string `yaml:"ignore" json:"ignore"`
+	Exporter struct {
+		IntervalMs int `yaml:"intervalMs" json:"intervalMs"`
+		Otlp       struct {
+			Enabled  bool   `yaml:"enabled" json:"enabled"`
+			Endpoint string `yaml:"endpoint" json:"endpoint"`
+			Insecure bool   `yaml:"insecure" json:"insecure"`
+		} `yaml:"otlp" json:"otlp"`
+		Stdout struct {
+			Enabled bool `yaml:"enabled" json:"enabled"`
+		} `yaml:"stdout" json:"stdout"`
+	} `yaml:"exporter" json:"exporter"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	entryName      string
+	entryType      string
+	pathToIgnore   []string
+	meterProvider  metric.MeterProvider
+	duration       metric.Float64Histogram
+	activeRequests metric.Int64UpDownCounter
+	requestSize    metric.Int64Histogram
+	responseSize   metric.Int64Histogram
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:    "fake-entry",
+		entryType:    "",
+		pathToIgnore: []string{},
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	// global MeterProvider would be used if missing, which is noop unless set by application
+	if set.meterProvider == nil {
+		set.meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := set.meterProvider.Meter(InstrumentationName)
+	fallback := noop.NewMeterProvider().Meter(InstrumentationName)
+
+	var err error
+	if set.duration, err = meter.Float64Histogram(MetricsNameDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Measures the duration of inbound HTTP requests.")); err != nil {
+		otel.Handle(err)
+		set.duration, _ = fallback.Float64Histogram(MetricsNameDuration)
+	}
+
+	if set.activeRequests, err = meter.Int64UpDownCounter(MetricsNameActiveRequests,
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Measures the number of concurrent HTTP requests that are currently in-flight.")); err != nil {
+		otel.Handle(err)
+		set.activeRequests, _ = fallback.Int64UpDownCounter(MetricsNameActiveRequests)
+	}
+
+	if set.requestSize, err = meter.Int64Histogram(MetricsNameRequestSize,
+		metric.WithUnit("By"),
+		metric.WithDescription("Measures the size of HTTP request messages.")); err != nil {
+		otel.Handle(err)
+		set.requestSize, _ = fallback.Int64Histogram(MetricsNameRequestSize)
+	}
+
+	if set.responseSize, err = meter.Int64Histogram(MetricsNameResponseSize,
+		metric.WithUnit("By"),
+		metric.WithDescription("Measures the size of HTTP response messages.")); err != nil {
+		otel.Handle(err)
+		set.responseSize, _ = fallback.Int64Histogram(MetricsNameResponseSize)
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// ShouldIgnore determine whether path should be ignored
+func (set *optionSet) ShouldIgnore(path string) bool {
+	for i := range set.pathToIgnore {
+		if strings.HasPrefix(path, set.pathToIgnore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(path)
+}
+
+// route returns route pattern matched by request, empty string would be returned if no route matched.
+func route(ctx *ghttp.Request) string {
+	if handler := ctx.GetServeHandler(); handler != nil && handler.Handler.Router != nil {
+		return handler.Handler.Router.Uri
+	}
+
+	return ""
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list, global MeterProvider would be used if provider is nil.
+func ToOptions(config *BootConfig, entryName, entryType string, provider metric.MeterProvider) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithPathToIgnore(config.Ignore...),
+			WithMeterProvider(provider))
+	}
+
+	return opts
+}
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithPathToIgnore provide paths prefix that will ignore.
+func WithPathToIgnore(paths ...string) Option {
+	return func(opt *optionSet) {
+		for i := range paths {
+			if len(paths[i]) > 0 {
+				opt.pathToIgnore = append(opt.pathToIgnore, paths[i])
+			}
+		}
+	}
+}
+
+// WithMeterProvider provide metric.MeterProvider, global MeterProvider would be used by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(opt *optionSet) {
+		if provider != nil {
+			opt.meterProvider = provider
+		}
+	}
+}
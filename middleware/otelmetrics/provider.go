@@ -0,0 +1,68 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfotelmetrics
+
+import (
+	"context"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"time"
+)
+
+// NewMeterProvider creates sdkmetric.MeterProvider which exports metrics periodically with exporters enabled
+// in BootConfig. nil would be returned if no exporter is enabled.
+//
+// Caller owns the MeterProvider and should shut it down in order to flush metrics.
+func NewMeterProvider(config *BootConfig) (*sdkmetric.MeterProvider, error) {
+	readerOpts := make([]sdkmetric.PeriodicReaderOption, 0)
+	if config.Exporter.IntervalMs > 0 {
+		readerOpts = append(readerOpts,
+			sdkmetric.WithInterval(time.Duration(config.Exporter.IntervalMs)*time.Millisecond))
+	}
+
+	providerOpts := make([]sdkmetric.Option, 0)
+
+	if config.Exporter.Otlp.Enabled {
+		opts := make([]otlpmetricgrpc.Option, 0)
+		if len(config.Exporter.Otlp.Endpoint) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(config.Exporter.Otlp.Endpoint))
+		}
+		if config.Exporter.Otlp.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+
+		exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		providerOpts = append(providerOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOpts...)))
+	}
+
+	if config.Exporter.Stdout.Enabled {
+		exporter, err := stdoutmetric.New()
+		if err != nil {
+			return nil, err
+		}
+		providerOpts = append(providerOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOpts...)))
+	}
+
+	if len(providerOpts) < 1 {
+		return nil, nil
+	}
+
+	res, err := sdkresource.Merge(sdkresource.Default(), sdkresource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),
+		semconv.ServiceVersion(rkentry.GlobalAppCtx.GetAppInfoEntry().Version)))
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkmetric.NewMeterProvider(append(providerOpts, sdkmetric.WithResource(res))...), nil
+}
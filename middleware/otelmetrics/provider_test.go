@@ -0,0 +1,85 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfotelmetrics
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"net"
+	"testing"
+	"time"
+)
+
+// otlpReceiver is an in-process OTLP collector which receives exported metrics.
+type otlpReceiver struct {
+	collectormetrics.UnimplementedMetricsServiceServer
+	requests chan *collectormetrics.ExportMetricsServiceRequest
+}
+
+func (r *otlpReceiver) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	r.requests <- req
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
+
+func TestNewMeterProvider(t *testing.T) {
+	// without exporter
+	provider, err := NewMeterProvider(&BootConfig{Enabled: true})
+	assert.Nil(t, err)
+	assert.Nil(t, provider)
+
+	// with stdout exporter
+	config := &BootConfig{Enabled: true}
+	config.Exporter.Stdout.Enabled = true
+	provider, err = NewMeterProvider(config)
+	assert.Nil(t, err)
+	assert.NotNil(t, provider)
+	assert.Nil(t, provider.Shutdown(context.TODO()))
+}
+
+func TestNewMeterProvider_WithOtlp(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	receiver := &otlpReceiver{requests: make(chan *collectormetrics.ExportMetricsServiceRequest, 10)}
+	server := grpc.NewServer()
+	collectormetrics.RegisterMetricsServiceServer(server, receiver)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	config := &BootConfig{Enabled: true}
+	config.Exporter.IntervalMs = 100
+	config.Exporter.Otlp.Enabled = true
+	config.Exporter.Otlp.Endpoint = lis.Addr().String()
+	config.Exporter.Otlp.Insecure = true
+
+	provider, err := NewMeterProvider(config)
+	assert.Nil(t, err)
+
+	set := newOptionSet(WithEntryNameAndType("ut-entry", "ut-type"), WithMeterProvider(provider))
+	set.duration.Record(context.TODO(), 0.1)
+	assert.Nil(t, provider.Shutdown(context.TODO()))
+
+	names := make([]string, 0)
+	timeout := time.After(5 * time.Second)
+	for len(names) < 1 {
+		select {
+		case req := <-receiver.requests:
+			for _, rm := range req.GetResourceMetrics() {
+				for _, sm := range rm.GetScopeMetrics() {
+					for _, m := range sm.GetMetrics() {
+						names = append(names, m.GetName())
+					}
+				}
+			}
+		case <-timeout:
+			t.Fatal("metrics not received by OTLP receiver")
+		}
+	}
+
+	assert.Contains(t, names, MetricsNameDuration)
+}
@@ -7,11 +7,16 @@
 package rkgfpanic
 
 import (
+	"fmt"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/panic"
 	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"go.uber.org/zap"
+	"net/http"
 )
 
 // Middleware returns a ghttp.HandlerFunc (middleware)
@@ -23,7 +28,7 @@ func Middleware(opts ...rkmidpanic.Option) ghttp.HandlerFunc {
 
 		handlerFunc := func(resp rkerror.ErrorInterface) {
 			ctx.Response.ClearBuffer()
-			ctx.Response.WriteStatus(resp.Code(), resp)
+			rkgfctx.WriteError(ctx, resp)
 		}
 		beforeCtx := set.BeforeCtx(rkgfctx.GetEvent(ctx), rkgfctx.GetLogger(ctx), handlerFunc)
 		set.Before(beforeCtx)
@@ -31,5 +36,22 @@ func Middleware(opts ...rkmidpanic.Option) ghttp.HandlerFunc {
 		defer beforeCtx.Output.DeferFunc()
 
 		ctx.Middleware.Next()
+
+		// panic of handler is recovered by GoFrame and set as error with gcode.CodeInternalPanic,
+		// it is written in envelope only if response middleware enabled, otherwise GoFrame writes it as before
+		if rkgfctx.GetEnvelope(ctx) == nil {
+			return
+		}
+
+		if err := ctx.GetError(); err != nil && gerror.Code(err) == gcode.CodeInternalPanic {
+			resp := rkgfctx.GetErrorBuilder(ctx).New(http.StatusInternalServerError, "Panic occurs", err)
+
+			event := rkgfctx.GetEvent(ctx)
+			event.SetCounter("panic", 1)
+			event.AddErr(resp)
+			rkgfctx.GetLogger(ctx).Error(fmt.Sprintf("panic occurs:\n%+v", err), zap.Error(resp))
+
+			handlerFunc(resp)
+		}
 	}
 }
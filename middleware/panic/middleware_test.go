@@ -34,6 +34,8 @@ func TestMiddleware(t *testing.T) {
 	resp, err := client.Get(context.TODO(), "/ut")
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	// body written by GoFrame is kept without response middleware
+	assert.NotContains(t, resp.ReadAllString(), "Panic occurs")
 	assert.Nil(t, server.Shutdown())
 
 	// without panic
@@ -0,0 +1,136 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfinter
+
+import (
+	"encoding/json"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"net/http"
+)
+
+const (
+	// ProblemContentType is content type of RFC 7807 problem details
+	ProblemContentType = "application/problem+json"
+	// ProblemTypeDefault is default problem type which means no additional semantics beyond HTTP status code
+	ProblemTypeDefault = "about:blank"
+	// JsonContentType is content type of google and amazon style errors
+	JsonContentType = "application/json; charset=utf-8"
+)
+
+// NewErrorBuilderProblem returns rkerror.ErrorBuilder which builds RFC 7807 problem details.
+func NewErrorBuilderProblem() rkerror.ErrorBuilder {
+	return &ErrorBuilderProblem{}
+}
+
+// ErrorBuilderProblem builds RFC 7807 problem details.
+type ErrorBuilderProblem struct{}
+
+// New creates ErrorProblem, message would be used as detail.
+func (e *ErrorBuilderProblem) New(code int, msg string, details ...interface{}) rkerror.ErrorInterface {
+	resp := &ErrorProblem{
+		Type:   ProblemTypeDefault,
+		Status: code,
+		Title:  http.StatusText(code),
+		Detail: msg,
+		Errors: make([]interface{}, 0),
+	}
+
+	if code < 1 {
+		resp.Status = http.StatusInternalServerError
+		resp.Title = http.StatusText(http.StatusInternalServerError)
+	}
+
+	for i := range details {
+		detail := details[i]
+		if v, ok := detail.(error); ok {
+			resp.Errors = append(resp.Errors, v.Error())
+		} else {
+			resp.Errors = append(resp.Errors, detail)
+		}
+	}
+
+	return resp
+}
+
+// NewCustom creates ErrorProblem with 500.
+func (e *ErrorBuilderProblem) NewCustom() rkerror.ErrorInterface {
+	return e.New(http.StatusInternalServerError, "")
+}
+
+// ErrorProblem is RFC 7807 problem details with request id, trace id and error details as extension members.
+// Referred RFC 7807: https://datatracker.ietf.org/doc/html/rfc7807
+type ErrorProblem struct {
+	Type      string        `json:"type" yaml:"type" example:"about:blank"`
+	Title     string        `json:"title" yaml:"title" example:"Internal Server Error"`
+	Status    int           `json:"status" yaml:"status" example:"500"`
+	Detail    string        `json:"detail,omitempty" yaml:"detail" example:"Internal error occurs"`
+	Instance  string        `json:"instance,omitempty" yaml:"instance" example:"/v1/greeter"`
+	RequestId string        `json:"requestId,omitempty" yaml:"requestId"`
+	TraceId   string        `json:"traceId,omitempty" yaml:"traceId"`
+	Errors    []interface{} `json:"errors,omitempty" yaml:"errors"`
+}
+
+// Code returns HTTP status code.
+func (err *ErrorProblem) Code() int {
+	return err.Status
+}
+
+// Message returns detail.
+func (err *ErrorProblem) Message() string {
+	return err.Detail
+}
+
+// Details returns errors.
+func (err *ErrorProblem) Details() []interface{} {
+	return err.Errors
+}
+
+// Error returns string of error
+func (err *ErrorProblem) Error() string {
+	res := "{}"
+
+	if bytes, marshalErr := json.Marshal(err); marshalErr == nil {
+		res = string(bytes)
+	}
+
+	return res
+}
+
+// WithRequest returns a copy of problem details with instance, request id and trace id filled.
+//
+// Request id and trace id are read from headers to return, which are set by meta and tracing middlewares.
+//
+// Errors may be written before meta middleware, like errors of jwt and csrf middlewares, request id would be
+// generated from request and set into headers to return in that case.
+func (err *ErrorProblem) WithRequest(req *http.Request, respHeader http.Header) *ErrorProblem {
+	res := *err
+
+	if req != nil && req.URL != nil {
+		res.Instance = req.URL.Path
+	}
+
+	if respHeader != nil {
+		res.RequestId = respHeader.Get(rkmid.HeaderRequestId)
+		res.TraceId = respHeader.Get(rkmid.HeaderTraceId)
+
+		if len(res.RequestId) < 1 {
+			res.RequestId = rkmid.GenerateRequestId(req)
+			respHeader.Set(rkmid.HeaderRequestId, res.RequestId)
+		}
+	}
+
+	return &res
+}
+
+// ErrorContentType returns content type of error response.
+func ErrorContentType(err rkerror.ErrorInterface) string {
+	if _, ok := err.(*ErrorProblem); ok {
+		return ProblemContentType
+	}
+
+	return JsonContentType
+}
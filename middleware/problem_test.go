@@ -0,0 +1,75 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfinter
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorBuilderProblem(t *testing.T) {
+	builder := NewErrorBuilderProblem()
+
+	// with details
+	err := builder.New(http.StatusUnauthorized, "ut-msg", errors.New("ut-err"), "ut-detail")
+	assert.Equal(t, http.StatusUnauthorized, err.Code())
+	assert.Equal(t, "ut-msg", err.Message())
+	assert.Equal(t, []interface{}{"ut-err", "ut-detail"}, err.Details())
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(err.Error()), &m))
+	assert.Equal(t, ProblemTypeDefault, m["type"])
+	assert.Equal(t, "Unauthorized", m["title"])
+	assert.Equal(t, float64(http.StatusUnauthorized), m["status"])
+	assert.Equal(t, "ut-msg", m["detail"])
+
+	// invalid code
+	err = builder.NewCustom()
+	assert.Equal(t, http.StatusInternalServerError, err.Code())
+	err = builder.New(0, "")
+	assert.Equal(t, http.StatusInternalServerError, err.Code())
+}
+
+func TestErrorProblem_WithRequest(t *testing.T) {
+	problem := NewErrorBuilderProblem().New(http.StatusForbidden, "ut-msg").(*ErrorProblem)
+
+	req := httptest.NewRequest(http.MethodGet, "/ut-path?key=secret", nil)
+	header := http.Header{}
+	header.Set(rkmid.HeaderRequestId, "ut-request-id")
+	header.Set(rkmid.HeaderTraceId, "ut-trace-id")
+
+	res := problem.WithRequest(req, header)
+	assert.Equal(t, "/ut-path", res.Instance)
+	assert.Equal(t, "ut-request-id", res.RequestId)
+	assert.Equal(t, "ut-trace-id", res.TraceId)
+
+	// original one should not be changed
+	assert.Empty(t, problem.Instance)
+
+	// without request id in headers to return
+	req.Header.Set(rkmid.HeaderRequestId, "ut-incoming-request-id")
+	header = http.Header{}
+	res = problem.WithRequest(req, header)
+	assert.Equal(t, "ut-incoming-request-id", res.RequestId)
+	assert.Equal(t, "ut-incoming-request-id", header.Get(rkmid.HeaderRequestId))
+
+	req.Header.Del(rkmid.HeaderRequestId)
+	header = http.Header{}
+	res = problem.WithRequest(req, header)
+	assert.NotEmpty(t, res.RequestId)
+	assert.Equal(t, res.RequestId, header.Get(rkmid.HeaderRequestId))
+}
+
+func TestErrorContentType(t *testing.T) {
+	assert.Equal(t, ProblemContentType, ErrorContentType(NewErrorBuilderProblem().New(http.StatusForbidden, "")))
+	assert.Equal(t, JsonContentType, ErrorContentType(rkerror.NewErrorBuilderGoogle().New(http.StatusForbidden, "")))
+}
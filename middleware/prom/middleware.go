@@ -11,21 +11,50 @@ import (
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/prom"
 	"strconv"
+	"time"
 )
 
 // Middleware create a new prometheus metrics interceptor with options.
+//
+// Use MiddlewareWithOptions() for metrics of size, in-flight requests, histogram and raw paths.
 func Middleware(opts ...rkmidprom.Option) ghttp.HandlerFunc {
-	set := rkmidprom.NewOptionSet(opts...)
+	return MiddlewareWithOptions(WithPromOptions(opts...))
+}
+
+// MiddlewareWithOptions create a new prometheus metrics interceptor with options of rkgfprom.
+func MiddlewareWithOptions(opts ...Option) ghttp.HandlerFunc {
+	set := newOptionSet(opts...)
 
 	return func(ctx *ghttp.Request) {
 		ctx.SetCtxVar(rkmid.EntryNameKey, set.GetEntryName())
 
+		// ignorance is determined by raw path
+		if set.ShouldIgnore(ctx.URL.Path) {
+			ctx.Middleware.Next()
+			return
+		}
+
+		// label metrics with matched route pattern instead of raw path
+		path := set.PathLabel(ctx)
+
 		beforeCtx := set.BeforeCtx(ctx.Request)
 		set.Before(beforeCtx)
 
+		endInFlight := set.StartInFlight(ctx.Method, path)
+		defer endInFlight()
+
 		ctx.Middleware.Next()
 
-		afterCtx := set.AfterCtx(strconv.Itoa(ctx.Response.Status))
+		beforeCtx.Input.RestPath = path
+		resCode := strconv.Itoa(ctx.Response.Status)
+
+		afterCtx := set.AfterCtx(resCode)
 		set.After(beforeCtx, afterCtx)
+
+		set.ObserveElapsed(ctx, path, resCode, time.Since(beforeCtx.Output.StartTime))
+
+		set.ObserveSize(ctx, path)
+
+		set.ObserveGcode(ctx, path)
 	}
 }
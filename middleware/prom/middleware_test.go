@@ -7,14 +7,22 @@ package rkgfprom
 
 import (
 	"context"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/gclient"
 	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/prom"
 	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/gcode"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"math/rand"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -32,6 +40,263 @@ func TestMiddleware(t *testing.T) {
 	assert.Nil(t, server.Shutdown())
 }
 
+func TestToOptions(t *testing.T) {
+	config := &BootConfig{RawPaths: []string{"/ut-raw"}}
+	assert.Empty(t, ToOptions(config, "ut-entry", "ut-type", prometheus.NewRegistry(), rkmidprom.LabelerTypeHttp))
+
+	config.Enabled = true
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type", prometheus.NewRegistry(), rkmidprom.LabelerTypeHttp)...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, []string{"/ut-raw"}, set.rawPaths)
+	assert.Nil(t, set.requestSize)
+	assert.Nil(t, set.inFlight)
+	assert.Nil(t, set.elapsed)
+
+	// with size and in-flight metrics
+	registry := prometheus.NewRegistry()
+	config.Size.Enabled = true
+	config.InFlight.Enabled = true
+	config.Histogram.Enabled = true
+	set = newOptionSet(ToOptions(config, "ut-entry", "ut-type", registry, rkmidprom.LabelerTypeHttp)...)
+	assert.NotNil(t, set.requestSize)
+	assert.NotNil(t, set.responseSize)
+	assert.NotNil(t, set.inFlight)
+	assert.NotNil(t, set.elapsed)
+	assert.Equal(t, registry, set.registerer)
+}
+
+func TestMatchPathPrefix(t *testing.T) {
+	assert.True(t, matchPathPrefix("/ut-raw", "/ut-raw"))
+	assert.True(t, matchPathPrefix("/ut-raw/1", "/ut-raw"))
+	assert.True(t, matchPathPrefix("/ut-raw/1", "/ut-raw/"))
+	assert.False(t, matchPathPrefix("/ut-rawv2", "/ut-raw"))
+	assert.False(t, matchPathPrefix("/ut", "/ut-raw"))
+	assert.False(t, matchPathPrefix("/ut", ""))
+}
+
+func TestTryRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	newCounter := func(labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Name: "ut_counter", Help: "ut"}, labels)
+	}
+
+	// registered
+	existing, err := tryRegister(registry, newCounter("ut_label"))
+	assert.Nil(t, existing)
+	assert.Nil(t, err)
+
+	// already registered
+	existing, err = tryRegister(registry, newCounter("ut_label"))
+	assert.NotNil(t, existing)
+	assert.Nil(t, err)
+
+	// conflict of labels
+	existing, err = tryRegister(registry, newCounter("ut_other_label"))
+	assert.Nil(t, existing)
+	assert.NotNil(t, err)
+	assert.Nil(t, register(registry, newCounter("ut_other_label")))
+}
+
+func TestMiddleware_WithRoutePattern(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	inter := MiddlewareWithOptions(
+		WithRegisterer(registry),
+		WithRawPaths("/ut-raw"))
+	server := startServer(t, func(ctx *ghttp.Request) {}, inter)
+	server.BindHandler("/ut-users/{id}", func(ctx *ghttp.Request) {
+		ctx.Response.Write(ctx.Get("id").String())
+	})
+	server.BindHandler("/ut-raw/{id}", func(ctx *ghttp.Request) {})
+
+	client := getClient()
+
+	// random ids share one series
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get(context.TODO(), "/ut-users/"+strconv.Itoa(rand.Int()))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_prom_resCode"))
+	assert.Equal(t, 1, countSeries(t, registry, "restPath", "/ut-users/{id}"))
+
+	// unmatched requests share one series
+	for i := 0; i < 20; i++ {
+		resp, err := client.Get(context.TODO(), "/ut-missing/"+strconv.Itoa(rand.Int()))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+	assert.Equal(t, 2, testutil.CollectAndCount(registry, "rk_prom_resCode"))
+	assert.Equal(t, 1, countSeries(t, registry, "restPath", UnmatchedPath))
+
+	// raw paths are kept
+	for _, id := range []string{"1", "2"} {
+		resp, err := client.Get(context.TODO(), "/ut-raw/"+id)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, 4, testutil.CollectAndCount(registry, "rk_prom_resCode"))
+	assert.Equal(t, 1, countSeries(t, registry, "restPath", "/ut-raw/1"))
+
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithSizeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	inter := MiddlewareWithOptions(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithRegisterer(registry),
+		WithSizeMetrics(true))
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-response")
+	}, inter)
+
+	client := getClient()
+	resp, err := client.Post(context.TODO(), "/ut", "ut-body")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+
+	assert.Equal(t, float64(len("ut-body")), histogramSum(t, registry, "rk_prom_requestSizeBytes"))
+	assert.Equal(t, float64(len("ut-response")), histogramSum(t, registry, "rk_prom_responseSizeBytes"))
+}
+
+func TestMiddleware_WithInFlightMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	set := newOptionSet(WithRegisterer(registry), WithInFlightMetrics(true))
+	gauge := set.inFlight.WithLabelValues("ut-entry", "ut-type", http.MethodGet, "/ut")
+
+	release := make(chan struct{})
+	inter := MiddlewareWithOptions(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithRegisterer(registry),
+		WithInFlightMetrics(true))
+	server := startServer(t, func(ctx *ghttp.Request) {
+		<-release
+	}, inter)
+
+	client := getClient()
+	done := make(chan struct{})
+	go func() {
+		resp, err := client.Get(context.TODO(), "/ut")
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+
+	close(release)
+	<-done
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithGcode(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	inter := MiddlewareWithOptions(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithRegisterer(registry))
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.SetError(gerror.NewCode(gcode.CodeNotFound, "ut-not-found"))
+	}, inter, rkgfgcode.Middleware())
+
+	client := getClient()
+	resp, err := client.Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+
+	set := newOptionSet(WithRegisterer(registry))
+	counter := set.gcode.WithLabelValues("ut-entry", "ut-type", http.MethodGet, "/ut", strconv.Itoa(gcode.CodeNotFound.Code()))
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "rk_prom_gcode"))
+}
+
+func TestMiddleware_WithExemplar(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	server := startServer(t, func(ctx *ghttp.Request) {},
+		MiddlewareWithOptions(WithRegisterer(registry), WithHistogram(true)),
+		// span injected by tracing middleware
+		func(ctx *ghttp.Request) {
+			ctx.SetCtxVar(rkmid.SpanKey, trace.SpanFromContext(trace.ContextWithSpanContext(context.TODO(), spanCtx)))
+			ctx.Middleware.Next()
+		})
+
+	client := getClient()
+	resp, err := client.Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+
+	traceIds := make([]string, 0)
+	for _, family := range families {
+		if family.GetName() != "rk_prom_elapsedSecond" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				for _, label := range bucket.GetExemplar().GetLabel() {
+					if label.GetName() == ExemplarTraceIdKey {
+						traceIds = append(traceIds, label.GetValue())
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, []string{spanCtx.TraceID().String()}, traceIds)
+}
+
+// histogramSum returns sample sum of histogram.
+func histogramSum(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+
+	res := float64(0)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			res += metric.GetHistogram().GetSampleSum()
+		}
+	}
+
+	return res
+}
+
+// countSeries returns count of series of rk_prom_resCode with label.
+func countSeries(t *testing.T, registry *prometheus.Registry, name, value string) int {
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+
+	res := 0
+	for _, family := range families {
+		if family.GetName() != "rk_prom_resCode" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == name && label.GetValue() == value {
+					res++
+				}
+			}
+		}
+	}
+
+	return res
+}
+
 func startServer(t *testing.T, usherHandler ghttp.HandlerFunc, inters ...ghttp.HandlerFunc) *ghttp.Server {
 	server := g.Server(rkmid.GenerateRequestId(nil))
 	server.SetPort(8080)
@@ -0,0 +1,360 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfprom
+
+import (
+	"errors"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/prom"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// UnmatchedPath is path label of requests which matched no route
+	UnmatchedPath = "unmatched"
+	// MetricsNameElapsedSecond records duration of request
+	MetricsNameElapsedSecond = "elapsedSecond"
+	// ExemplarTraceIdKey is label key of trace id in exemplar
+	ExemplarTraceIdKey = "trace_id"
+	// MetricsNameRequestSize records size of request body
+	MetricsNameRequestSize = "requestSizeBytes"
+	// MetricsNameResponseSize records size of response body
+	MetricsNameResponseSize = "responseSizeBytes"
+	// MetricsNameInFlight records requests being served
+	MetricsNameInFlight = "inFlight"
+	// MetricsNameGcode counts errors mapped by gcode middleware
+	MetricsNameGcode = "gcode"
+)
+
+// labelKeys are labels of size and in-flight metrics
+var labelKeys = []string{
+	"entryName",
+	"entryType",
+	"restMethod",
+	"restPath",
+}
+
+// sizeBuckets are buckets of size histograms from 100B to 100MB
+var sizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+
+// BootConfig for YAML
+type BootConfig struct {
+	rkmidprom.BootConfig `yaml:",inline" json:",inline" mapstructure:",squash"`
+	RawPaths             []string `yaml:"rawPaths" json:"rawPaths"`
+	Size                 struct {
+		Enabled bool `yaml:"enabled" json:"enabled"`
+	} `yaml:"size" json:"size"`
+	InFlight struct {
+		Enabled bool `yaml:"enabled" json:"enabled"`
+	} `yaml:"inFlight" json:"inFlight"`
+	Histogram struct {
+		Enabled bool `yaml:"enabled" json:"enabled"`
+	} `yaml:"histogram" json:"histogram"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	rkmidprom.OptionSetInterface
+	promOpts         []rkmidprom.Option
+	rawPaths         []string
+	registerer       prometheus.Registerer
+	sizeEnabled      bool
+	inFlightEnabled  bool
+	histogramEnabled bool
+	elapsed          *prometheus.HistogramVec
+	requestSize      *prometheus.HistogramVec
+	responseSize     *prometheus.HistogramVec
+	inFlight         *prometheus.GaugeVec
+	gcode            *prometheus.CounterVec
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		promOpts:   []rkmidprom.Option{},
+		rawPaths:   []string{},
+		registerer: prometheus.DefaultRegisterer,
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	set.OptionSetInterface = rkmidprom.NewOptionSet(set.promOpts...)
+
+	if set.histogramEnabled {
+		set.elapsed = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "rk",
+			Subsystem: "prom",
+			Name:      MetricsNameElapsedSecond,
+			Help:      "Histogram of request duration with trace id as exemplar",
+			Buckets:   prometheus.DefBuckets,
+		}, append(labelKeys, "resCode"))
+		if existing, ok := register(set.registerer, set.elapsed).(*prometheus.HistogramVec); ok {
+			set.elapsed = existing
+		}
+	}
+
+	set.gcode = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "rk",
+		Subsystem: "prom",
+		Name:      MetricsNameGcode,
+		Help:      "Counter of errors mapped by gcode middleware",
+	}, append(labelKeys, "gcode"))
+	if existing, ok := register(set.registerer, set.gcode).(*prometheus.CounterVec); ok {
+		set.gcode = existing
+	}
+
+	if set.sizeEnabled {
+		set.requestSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "rk",
+			Subsystem: "prom",
+			Name:      MetricsNameRequestSize,
+			Help:      "Histogram of request body size",
+			Buckets:   sizeBuckets,
+		}, labelKeys)
+		if existing, ok := register(set.registerer, set.requestSize).(*prometheus.HistogramVec); ok {
+			set.requestSize = existing
+		}
+
+		set.responseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "rk",
+			Subsystem: "prom",
+			Name:      MetricsNameResponseSize,
+			Help:      "Histogram of response body size",
+			Buckets:   sizeBuckets,
+		}, labelKeys)
+		if existing, ok := register(set.registerer, set.responseSize).(*prometheus.HistogramVec); ok {
+			set.responseSize = existing
+		}
+	}
+
+	if set.inFlightEnabled {
+		set.inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "rk",
+			Subsystem: "prom",
+			Name:      MetricsNameInFlight,
+			Help:      "Gauge of requests being served",
+		}, labelKeys)
+		if existing, ok := register(set.registerer, set.inFlight).(*prometheus.GaugeVec); ok {
+			set.inFlight = existing
+		}
+	}
+
+	return set
+}
+
+// register registers collector, existing collector would be returned if registered by another middleware.
+//
+// Other errors like conflict of labels would be logged, and collector would be used without being exported.
+func register(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	existing, err := tryRegister(registerer, collector)
+	if err != nil {
+		rkentry.GlobalAppCtx.GetLoggerEntryDefault().Error("Failed to register prometheus collector", zap.Error(err))
+	}
+
+	return existing
+}
+
+// tryRegister registers collector, existing collector would be returned if registered by another middleware.
+func tryRegister(registerer prometheus.Registerer, collector prometheus.Collector) (prometheus.Collector, error) {
+	if err := registerer.Register(collector); err != nil {
+		are := prometheus.AlreadyRegisteredError{}
+		if errors.As(err, &are) {
+			return are.ExistingCollector, nil
+		}
+
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// PathLabel returns path label of request.
+//
+// Route pattern matched by request would be used, like /users/{id}, in order to bound cardinality of metrics.
+// UnmatchedPath would be used if no route matched, raw path would be used if it is in raw paths with whole segments matched.
+func (set *optionSet) PathLabel(ctx *ghttp.Request) string {
+	for i := range set.rawPaths {
+		if matchPathPrefix(ctx.URL.Path, set.rawPaths[i]) {
+			return ctx.URL.Path
+		}
+	}
+
+	if handler := ctx.GetServeHandler(); handler != nil && handler.Handler.Router != nil {
+		return handler.Handler.Router.Uri
+	}
+
+	return UnmatchedPath
+}
+
+// matchPathPrefix checks whether path equals to prefix or starts with prefix followed by "/".
+//
+// Whole segments are matched, /api matches /api and /api/users, but not /apiv2.
+func matchPathPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if len(prefix) < 1 {
+		return false
+	}
+
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// ObserveElapsed records duration of request of route if histogram enabled.
+//
+// Trace id of sampled span would be attached as exemplar, so that trace could be found from metrics.
+func (set *optionSet) ObserveElapsed(ctx *ghttp.Request, path, resCode string, elapsed time.Duration) {
+	if set.elapsed == nil {
+		return
+	}
+
+	observer := set.elapsed.WithLabelValues(set.GetEntryName(), set.GetEntryType(), ctx.Method, path, resCode)
+
+	if spanCtx := rkgfctx.GetTraceSpan(ctx).SpanContext(); spanCtx.IsValid() && spanCtx.IsSampled() {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(elapsed.Seconds(), prometheus.Labels{
+				ExemplarTraceIdKey: spanCtx.TraceID().String(),
+			})
+			return
+		}
+	}
+
+	observer.Observe(elapsed.Seconds())
+}
+
+// ObserveGcode counts gcode of request mapped by gcode middleware, nothing would be counted if gcode is missing.
+func (set *optionSet) ObserveGcode(ctx *ghttp.Request, path string) {
+	code := ctx.GetCtxVar(rkgfinter.GcodeKey)
+	if code.IsNil() {
+		return
+	}
+
+	set.gcode.WithLabelValues(set.GetEntryName(), set.GetEntryType(), ctx.Method, path, code.String()).Inc()
+}
+
+// StartInFlight increases in-flight gauge of route, returned function decreases it.
+func (set *optionSet) StartInFlight(method, path string) func() {
+	if set.inFlight == nil {
+		return func() {}
+	}
+
+	gauge := set.inFlight.WithLabelValues(set.GetEntryName(), set.GetEntryType(), method, path)
+	gauge.Inc()
+
+	return gauge.Dec
+}
+
+// ObserveSize records size of request and response body of route.
+//
+// Size of request body is read from Content-Length of request, which would be skipped if unknown.
+// Size of response body is length of buffer, or Content-Length of response if buffer is flushed already.
+func (set *optionSet) ObserveSize(ctx *ghttp.Request, path string) {
+	if set.requestSize == nil || set.responseSize == nil {
+		return
+	}
+
+	values := []string{set.GetEntryName(), set.GetEntryType(), ctx.Method, path}
+
+	if ctx.Request.ContentLength >= 0 {
+		set.requestSize.WithLabelValues(values...).Observe(float64(ctx.Request.ContentLength))
+	}
+
+	resSize := int64(ctx.Response.BufferLength())
+	if resSize < 1 {
+		if v, err := strconv.ParseInt(ctx.Response.Header().Get("Content-Length"), 10, 64); err == nil {
+			resSize = v
+		}
+	}
+	set.responseSize.WithLabelValues(values...).Observe(float64(resSize))
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string, reg *prometheus.Registry, labelerType string) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithPromOptions(rkmidprom.ToOptions(&config.BootConfig, entryName, entryType, reg, labelerType)...),
+			WithRawPaths(config.RawPaths...),
+			WithSizeMetrics(config.Size.Enabled),
+			WithInFlightMetrics(config.InFlight.Enabled),
+			WithHistogram(config.Histogram.Enabled))
+
+		if reg != nil {
+			opts = append(opts, WithRegisterer(reg))
+		}
+	}
+
+	return opts
+}
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithPromOptions provide options of rkmidprom.
+func WithPromOptions(opts ...rkmidprom.Option) Option {
+	return func(opt *optionSet) {
+		opt.promOpts = append(opt.promOpts, opts...)
+	}
+}
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return WithPromOptions(rkmidprom.WithEntryNameAndType(entryName, entryType))
+}
+
+// WithRegisterer provide prometheus.Registerer.
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(opt *optionSet) {
+		if registerer != nil {
+			opt.registerer = registerer
+			opt.promOpts = append(opt.promOpts, rkmidprom.WithRegisterer(registerer))
+		}
+	}
+}
+
+// WithSizeMetrics enable histograms of request and response body size.
+func WithSizeMetrics(enabled bool) Option {
+	return func(opt *optionSet) {
+		opt.sizeEnabled = enabled
+	}
+}
+
+// WithInFlightMetrics enable gauge of requests being served.
+func WithInFlightMetrics(enabled bool) Option {
+	return func(opt *optionSet) {
+		opt.inFlightEnabled = enabled
+	}
+}
+
+// WithHistogram enable histogram of request duration with trace id as exemplar.
+//
+// Duration is recorded in summary of rkmidprom already, histogram is recorded in addition to it, not instead of it.
+func WithHistogram(enabled bool) Option {
+	return func(opt *optionSet) {
+		opt.histogramEnabled = enabled
+	}
+}
+
+// WithRawPaths provide path prefixes whose raw path would be used as label instead of matched route pattern.
+//
+// Be careful, every distinct path would create new series.
+func WithRawPaths(paths ...string) Option {
+	return func(opt *optionSet) {
+		opt.rawPaths = append(opt.rawPaths, paths...)
+	}
+}
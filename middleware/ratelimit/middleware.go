@@ -10,6 +10,7 @@ import (
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/rookie-ninja/rk-entry/v2/middleware/ratelimit"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
 )
 
 // Middleware Add rate limit interceptors.
@@ -23,7 +24,7 @@ func Middleware(opts ...rkmidlimit.Option) ghttp.HandlerFunc {
 		set.Before(beforeCtx)
 
 		if beforeCtx.Output.ErrResp != nil {
-			ctx.Response.WriteStatus(beforeCtx.Output.ErrResp.Code(), beforeCtx.Output.ErrResp)
+			rkgfctx.WriteError(ctx, beforeCtx.Output.ErrResp)
 			return
 		}
 
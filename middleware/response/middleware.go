@@ -0,0 +1,98 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfresp is a middleware of GoFrame framework for wrapping response of handler in unified JSON envelope
+package rkgfresp
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps response of handler in envelope of code, message, data, request id and trace id.
+//
+// Errors written by rkgfctx.WriteError() would be wrapped in the same envelope, including errors of panic middleware.
+// Panic recovered by GoFrame is left to panic middleware.
+// Custom content written by handler, static file, streaming and hijacked responses are skipped.
+func Middleware(opts ...Option) ghttp.HandlerFunc {
+	set := newOptionSet(opts...)
+
+	return func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.EntryNameKey, set.GetEntryName())
+
+		if set.ShouldIgnore(ctx.URL.Path) {
+			ctx.Middleware.Next()
+			return
+		}
+
+		ctx.SetCtxVar(rkgfinter.EnvelopeKey, set.envelope)
+
+		ctx.Middleware.Next()
+
+		if ctx.IsFileRequest() || isStreaming(ctx) {
+			return
+		}
+
+		err := ctx.GetError()
+
+		// panic recovered by GoFrame is written by panic middleware
+		if err != nil && gerror.Code(err) == gcode.CodeInternalPanic {
+			return
+		}
+
+		// handler error without custom content
+		if err != nil && ctx.Response.BufferLength() < 1 {
+			ctx.Response.ClearBuffer()
+
+			code := ctx.Response.Status
+			if code < http.StatusBadRequest {
+				code = http.StatusInternalServerError
+			}
+
+			rkgfctx.WriteError(ctx, rkgfctx.GetErrorBuilder(ctx).New(code, err.Error()))
+			return
+		}
+
+		// custom content written by handler
+		if ctx.Response.BufferLength() > 0 {
+			return
+		}
+
+		code := ctx.Response.Status
+		if code < 1 {
+			code = http.StatusOK
+		}
+
+		msg := ""
+		if code >= http.StatusBadRequest {
+			msg = http.StatusText(code)
+		}
+
+		rkgfctx.WriteEnvelope(ctx, code, msg, ctx.GetHandlerResponse())
+	}
+}
+
+// isStreaming checks whether response has been flushed, hijacked or is server-sent events.
+func isStreaming(ctx *ghttp.Request) bool {
+	if strings.HasPrefix(ctx.Response.Header().Get(rkmid.HeaderContentType), "text/event-stream") {
+		return true
+	}
+
+	if w, ok := ctx.Response.RawWriter().(interface{ IsHeaderWrote() bool }); ok && w.IsHeaderWrote() {
+		return true
+	}
+
+	if w, ok := ctx.Response.RawWriter().(interface{ IsHijacked() bool }); ok && w.IsHijacked() {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfresp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-entry/v2/middleware/panic"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"github.com/rookie-ninja/rk-gf/middleware/panic"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestToOptions(t *testing.T) {
+	config := &BootConfig{Enabled: false}
+	assert.Empty(t, ToOptions(config, "ut-entry", "ut-type"))
+
+	config.Enabled = true
+	config.Ignore = []string{"/ut-ignore"}
+	config.Keys.Code = "ut-code"
+	config.Keys.TraceId = rkgfinter.EnvelopeOmitted
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type")...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, "ut-code", set.envelope.CodeKey)
+	assert.Equal(t, "message", set.envelope.MessageKey)
+	assert.Equal(t, rkgfinter.EnvelopeOmitted, set.envelope.TraceIdKey)
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+	assert.False(t, set.ShouldIgnore("/ut"))
+}
+
+type utReq struct {
+	g.Meta `method:"get"`
+}
+
+type utRes struct {
+	Name string `json:"name"`
+}
+
+func TestMiddleware_WithHandlerResponse(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {}, Middleware(WithDataKey("result")))
+	server.BindHandler("/ut-typed", func(ctx context.Context, req *utReq) (*utRes, error) {
+		ghttp.RequestFromCtx(ctx).Response.Header().Set(rkgfctx.RequestIdKey, "ut-request-id")
+		return &utRes{Name: "ut-name"}, nil
+	})
+
+	resp, err := getClient().Get(context.TODO(), "/ut-typed")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(resp.ReadAll(), &m))
+	assert.Equal(t, float64(http.StatusOK), m["code"])
+	assert.Equal(t, "", m["message"])
+	assert.Equal(t, map[string]interface{}{"name": "ut-name"}, m["result"])
+	assert.Equal(t, "ut-request-id", m["requestId"])
+	assert.Contains(t, m, "traceId")
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithCustomContent(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.Write("ut-content")
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ut-content", resp.ReadAllString())
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithStreaming(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.Header().Set("Content-Type", "text/event-stream")
+		ctx.Response.Write("data: ut-event\n\n")
+		ctx.Response.Flush()
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, "data: ut-event\n\n", resp.ReadAllString())
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithError(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.SetError(errors.New("ut-error"))
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(resp.ReadAll(), &m))
+	assert.Equal(t, float64(http.StatusInternalServerError), m["code"])
+	assert.Equal(t, "ut-error", m["message"])
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithPanic(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		panic(errors.New("ut-panic"))
+	}, rkgfpanic.Middleware(rkmidpanic.WithEntryNameAndType("ut-entry", "ut-type")), Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(resp.ReadAll(), &m))
+	assert.Equal(t, float64(http.StatusInternalServerError), m["code"])
+	assert.Equal(t, "Panic occurs", m["message"])
+	assert.NotEmpty(t, m["data"])
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithIgnore(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.WriteHeader(http.StatusOK)
+	}, Middleware(WithPathToIgnore("/ut")))
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.ReadAllString())
+	assert.Nil(t, server.Shutdown())
+}
+
+func startServer(t *testing.T, usherHandler ghttp.HandlerFunc, inters ...ghttp.HandlerFunc) *ghttp.Server {
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8092)
+	server.SetDumpRouterMap(false)
+	server.BindMiddlewareDefault(inters...)
+	server.BindHandler("/ut", usherHandler)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	assert.Nil(t, server.Start())
+
+	return server
+}
+
+func getClient() *gclient.Client {
+	time.Sleep(100 * time.Millisecond)
+	client := g.Client()
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://127.0.0.1:8092")
+
+	return client
+}
+
+func assertNotPanic(t *testing.T) {
+	if r := recover(); r != nil {
+		// Expect panic to be called with non nil error
+		assert.True(t, false)
+	} else {
+		// This should never be called in case of a bug
+		assert.True(t, true)
+	}
+}
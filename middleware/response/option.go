@@ -0,0 +1,159 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfresp
+
+import (
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"strings"
+)
+
+// BootConfig for YAML
+type BootConfig struct {
+	Enabled bool     `yaml:"enabled" json:"enabled"`
+	Ignore  []string `yaml:"ignore" json:"ignore"`
+	Keys    struct {
+		Code      string `yaml:"code" json:"code"`
+		Message   string `yaml:"message" json:"message"`
+		Data      string `yaml:"data" json:"data"`
+		RequestId string `yaml:"requestId" json:"requestId"`
+		TraceId   string `yaml:"traceId" json:"traceId"`
+	} `yaml:"keys" json:"keys"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	entryName    string
+	entryType    string
+	envelope     *rkgfinter.Envelope
+	pathToIgnore []string
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:    "fake-entry",
+		entryType:    "",
+		envelope:     rkgfinter.NewEnvelope(),
+		pathToIgnore: []string{},
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// ShouldIgnore determine whether path should be ignored
+func (set *optionSet) ShouldIgnore(path string) bool {
+	for i := range set.pathToIgnore {
+		if strings.HasPrefix(path, set.pathToIgnore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(path)
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithPathToIgnore(config.Ignore...),
+			WithCodeKey(config.Keys.Code),
+			WithMessageKey(config.Keys.Message),
+			WithDataKey(config.Keys.Data),
+			WithRequestIdKey(config.Keys.RequestId),
+			WithTraceIdKey(config.Keys.TraceId))
+	}
+
+	return opts
+}
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithCodeKey provide key of code in envelope, use rkgfinter.EnvelopeOmitted to omit it.
+func WithCodeKey(key string) Option {
+	return func(opt *optionSet) {
+		if len(key) > 0 {
+			opt.envelope.CodeKey = key
+		}
+	}
+}
+
+// WithMessageKey provide key of message in envelope, use rkgfinter.EnvelopeOmitted to omit it.
+func WithMessageKey(key string) Option {
+	return func(opt *optionSet) {
+		if len(key) > 0 {
+			opt.envelope.MessageKey = key
+		}
+	}
+}
+
+// WithDataKey provide key of data in envelope, use rkgfinter.EnvelopeOmitted to omit it.
+func WithDataKey(key string) Option {
+	return func(opt *optionSet) {
+		if len(key) > 0 {
+			opt.envelope.DataKey = key
+		}
+	}
+}
+
+// WithRequestIdKey provide key of request id in envelope, use rkgfinter.EnvelopeOmitted to omit it.
+func WithRequestIdKey(key string) Option {
+	return func(opt *optionSet) {
+		if len(key) > 0 {
+			opt.envelope.RequestIdKey = key
+		}
+	}
+}
+
+// WithTraceIdKey provide key of trace id in envelope, use rkgfinter.EnvelopeOmitted to omit it.
+func WithTraceIdKey(key string) Option {
+	return func(opt *optionSet) {
+		if len(key) > 0 {
+			opt.envelope.TraceIdKey = key
+		}
+	}
+}
+
+// WithPathToIgnore provide paths prefix that will ignore.
+func WithPathToIgnore(paths ...string) Option {
+	return func(set *optionSet) {
+		for i := range paths {
+			if len(paths[i]) > 0 {
+				set.pathToIgnore = append(set.pathToIgnore, paths[i])
+			}
+		}
+	}
+}
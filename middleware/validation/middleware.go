@@ -0,0 +1,115 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkgfvalid is a middleware of GoFrame framework for rendering gvalid errors with rk error model
+package rkgfvalid
+
+import (
+	"errors"
+	"github.com/gogf/gf/v2/i18n/gi18n"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/util/gvalid"
+	"github.com/rookie-ninja/rk-entry/v2/error"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware/context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldViolation is detail of error response which describes failed validation rule of field.
+type FieldViolation struct {
+	Field       string `json:"field" yaml:"field"`
+	Rule        string `json:"rule" yaml:"rule"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// Middleware renders gvalid errors of handler as 400 with field violations as details in error model of entry.
+//
+// Language of gi18n is set into context from Accept-Language header, so that messages of gvalid would be translated.
+func Middleware(opts ...Option) ghttp.HandlerFunc {
+	set := newOptionSet(opts...)
+
+	return func(ctx *ghttp.Request) {
+		ctx.SetCtxVar(rkmid.EntryNameKey, set.GetEntryName())
+
+		if set.ShouldIgnore(ctx.URL.Path) {
+			ctx.Middleware.Next()
+			return
+		}
+
+		if lang := AcceptLanguage(ctx.Header.Get("Accept-Language"), set.defaultLanguage); len(lang) > 0 {
+			ctx.SetCtx(gi18n.WithLanguage(ctx.Context(), lang))
+		}
+
+		ctx.Middleware.Next()
+
+		var vErr gvalid.Error
+		if err := ctx.GetError(); err == nil || !errors.As(err, &vErr) {
+			return
+		}
+
+		event := rkgfctx.GetEvent(ctx)
+		event.IncCounter("validationError", 1)
+		event.AddErr(vErr)
+
+		ctx.Response.ClearBuffer()
+		rkgfctx.WriteError(ctx, NewError(ctx, vErr))
+	}
+}
+
+// NewError creates error of 400 in error model of entry with first message as message and FieldViolation as details.
+func NewError(ctx *ghttp.Request, err gvalid.Error) rkerror.ErrorInterface {
+	details := make([]interface{}, 0)
+
+	for _, item := range err.Items() {
+		for field, rules := range item {
+			for _, rule := range sortedKeys(rules) {
+				details = append(details, &FieldViolation{
+					Field:       field,
+					Rule:        rule,
+					Description: rules[rule].Error(),
+				})
+			}
+		}
+	}
+
+	return rkgfctx.GetErrorBuilder(ctx).New(http.StatusBadRequest, err.FirstError().Error(), details...)
+}
+
+// AcceptLanguage returns language with highest quality in Accept-Language header, defaultLang would be returned if missing.
+func AcceptLanguage(header, defaultLang string) string {
+	res, quality := defaultLang, 0.0
+
+	for _, tag := range strings.Split(header, ",") {
+		lang, q := tag, 1.0
+		if i := strings.Index(tag, ";"); i >= 0 {
+			lang = tag[:i]
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(tag[i+1:]), "q="), 64); err == nil {
+				q = v
+			}
+		}
+
+		lang = strings.TrimSpace(lang)
+		if len(lang) < 1 || lang == "*" || q <= quality {
+			continue
+		}
+
+		res, quality = lang, q
+	}
+
+	return res
+}
+
+func sortedKeys(m map[string]error) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
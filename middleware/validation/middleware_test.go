@@ -0,0 +1,134 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfvalid
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/i18n/gi18n"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"github.com/rookie-ninja/rk-gf/middleware"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestToOptions(t *testing.T) {
+	config := &BootConfig{Enabled: false, DefaultLanguage: "zh-CN"}
+	assert.Empty(t, ToOptions(config, "ut-entry", "ut-type"))
+
+	config.Enabled = true
+	config.Ignore = []string{"/ut-ignore"}
+	set := newOptionSet(ToOptions(config, "ut-entry", "ut-type")...)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, "zh-CN", set.defaultLanguage)
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+	assert.False(t, set.ShouldIgnore("/ut"))
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "", AcceptLanguage("", ""))
+	assert.Equal(t, "en", AcceptLanguage("", "en"))
+	assert.Equal(t, "en", AcceptLanguage("*", "en"))
+	assert.Equal(t, "zh-CN", AcceptLanguage("zh-CN", "en"))
+	assert.Equal(t, "zh-CN", AcceptLanguage("zh-CN,zh;q=0.9,en;q=0.8", "en"))
+	assert.Equal(t, "ja", AcceptLanguage("en;q=0.5, ja", ""))
+	assert.Equal(t, "en", AcceptLanguage("zh;q=0.1,en;q=0.8", ""))
+}
+
+func TestMiddleware_WithValidationError(t *testing.T) {
+	defer assertNotPanic(t)
+
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(path.Join(dir, "zh-CN.toml"), []byte(`"gf.gvalid.rule.required" = "{field}不能为空"`), 0644))
+	assert.Nil(t, gi18n.SetPath(dir))
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.SetError(g.Validator().Rules(map[string]string{
+			"name": "required",
+			"id":   "required|min:1",
+		}).Data(map[string]interface{}{}).Run(ctx.Context()))
+	}, Middleware())
+
+	// default language
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	m := map[string]map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(resp.ReadAll(), &m))
+	assert.Equal(t, float64(http.StatusBadRequest), m["error"]["code"])
+	assert.Contains(t, m["error"]["message"], "is required")
+
+	details := m["error"]["details"].([]interface{})
+	assert.Len(t, details, 3)
+	assert.Contains(t, details, map[string]interface{}{
+		"field":       "name",
+		"rule":        "required",
+		"description": "The name field is required",
+	})
+
+	// translated with Accept-Language
+	client := getClient()
+	client.SetHeader("Accept-Language", "zh-CN,zh;q=0.9")
+	resp, err = client.Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resp.ReadAllString(), "name不能为空")
+
+	assert.Nil(t, server.Shutdown())
+}
+
+func TestMiddleware_WithoutValidationError(t *testing.T) {
+	defer assertNotPanic(t)
+
+	server := startServer(t, func(ctx *ghttp.Request) {
+		ctx.Response.WriteHeader(http.StatusOK)
+	}, Middleware())
+
+	resp, err := getClient().Get(context.TODO(), "/ut")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, server.Shutdown())
+}
+
+func startServer(t *testing.T, usherHandler ghttp.HandlerFunc, inters ...ghttp.HandlerFunc) *ghttp.Server {
+	server := g.Server(rkmid.GenerateRequestId(nil))
+	server.SetPort(8093)
+	server.SetDumpRouterMap(false)
+	server.BindMiddlewareDefault(inters...)
+	server.BindHandler("/ut", usherHandler)
+	server.SetLogger(rkgfinter.NewNoopGLogger())
+	assert.Nil(t, server.Start())
+
+	return server
+}
+
+func getClient() *gclient.Client {
+	time.Sleep(100 * time.Millisecond)
+	client := g.Client()
+	client.SetBrowserMode(true)
+	client.SetPrefix("http://127.0.0.1:8093")
+
+	return client
+}
+
+func assertNotPanic(t *testing.T) {
+	if r := recover(); r != nil {
+		// Expect panic to be called with non nil error
+		assert.True(t, false)
+	} else {
+		// This should never be called in case of a bug
+		assert.True(t, true)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkgfvalid
+
+import (
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"strings"
+)
+
+// BootConfig for YAML
+type BootConfig struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled"`
+	Ignore          []string `yaml:"ignore" json:"ignore"`
+	DefaultLanguage string   `yaml:"defaultLanguage" json:"defaultLanguage"`
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	entryName       string
+	entryType       string
+	defaultLanguage string
+	pathToIgnore    []string
+}
+
+// newOptionSet Create new optionSet with options.
+func newOptionSet(opts ...Option) *optionSet {
+	set := &optionSet{
+		entryName:    "fake-entry",
+		entryType:    "",
+		pathToIgnore: []string{},
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// ShouldIgnore determine whether path should be ignored
+func (set *optionSet) ShouldIgnore(path string) bool {
+	for i := range set.pathToIgnore {
+		if strings.HasPrefix(path, set.pathToIgnore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(path)
+}
+
+// ***************** Option *****************
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithPathToIgnore(config.Ignore...),
+			WithDefaultLanguage(config.DefaultLanguage))
+	}
+
+	return opts
+}
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithDefaultLanguage provide language of gi18n used if Accept-Language is missing in request.
+func WithDefaultLanguage(lang string) Option {
+	return func(opt *optionSet) {
+		opt.defaultLanguage = lang
+	}
+}
+
+// WithPathToIgnore provide paths prefix that will ignore.
+func WithPathToIgnore(paths ...string) Option {
+	return func(set *optionSet) {
+		for i := range paths {
+			if len(paths[i]) > 0 {
+				set.pathToIgnore = append(set.pathToIgnore, paths[i])
+			}
+		}
+	}
+}